@@ -0,0 +1,192 @@
+package main
+
+import (
+	"SpaceDroid/sim"
+	"SpaceDroid/synth"
+	"embed"
+	"errors"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// The default assets are built into the binary, so it runs from anywhere.
+//
+//go:embed assets
+var embeddedAssets embed.FS
+
+// Assets loads game files by their path under assets, such as
+// "audio/win.wav". Each is looked for in the override directory first, so
+// modders can replace single files, and otherwise comes from the binary.
+type Assets struct {
+	// Override is the directory searched first, empty for none
+	Override string
+	// streamed keeps the files music is streamed from alive, since raylib
+	// reads them as it plays rather than copying them
+	streamed [][]byte
+}
+
+// NewAssets searches dir before the built in assets, or the assets folder in
+// the game's config directory when dir is empty.
+func NewAssets(dir string) *Assets {
+	if dir == "" {
+		var path, err = ConfigPath("assets")
+		if err != nil {
+			rl.TraceLog(rl.LogWarning, "ASSETS: Failed to find config directory: %s", err.Error())
+			return &Assets{}
+		}
+		return &Assets{Override: path}
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		rl.TraceLog(rl.LogWarning, "ASSETS: Override directory %s not found, using the built in assets", dir)
+	}
+	return &Assets{Override: dir}
+}
+
+// readOverride reads name from the override directory, quietly reporting
+// false when it isn't there.
+func (a *Assets) readOverride(name string) (string, []byte, bool) {
+	if a.Override == "" {
+		return "", nil, false
+	}
+
+	var file = filepath.Join(a.Override, filepath.FromSlash(name))
+	var contents, err = os.ReadFile(file)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			rl.TraceLog(rl.LogError, "ASSETS: Failed to read override %s: %s", file, err.Error())
+		}
+		return file, nil, false
+	}
+	return file, contents, true
+}
+
+// Overridden reports whether name is in the override directory, for assets
+// that are made some other way when it isn't.
+func (a *Assets) Overridden(name string) bool {
+	if a.Override == "" {
+		return false
+	}
+	var info, err = os.Stat(filepath.Join(a.Override, filepath.FromSlash(name)))
+	return err == nil && !info.IsDir()
+}
+
+// loadAsset decodes name from the override directory, falling back to the
+// built in copy when there is no override or it can't be decoded.
+func loadAsset[T any](a *Assets, name string, decode func(contents []byte) (T, bool)) (T, bool) {
+	var none T
+	var embedded = path.Join("assets", name)
+	if file, contents, ok := a.readOverride(name); ok {
+		if value, ok := decode(contents); ok {
+			rl.TraceLog(rl.LogInfo, "ASSETS: Using override %s", file)
+			return value, true
+		}
+		if _, err := fs.Stat(embeddedAssets, embedded); err != nil {
+			rl.TraceLog(rl.LogError, "ASSETS: Failed to decode %s", file)
+			return none, false
+		}
+		rl.TraceLog(rl.LogError, "ASSETS: Failed to decode override %s, using the built in one", file)
+	}
+
+	var contents, err = embeddedAssets.ReadFile(embedded)
+	if err != nil {
+		rl.TraceLog(rl.LogError, "ASSETS: No built in %s", name)
+		return none, false
+	}
+	return decode(contents)
+}
+
+// List names the files in dir, such as "levels", across the override
+// directory and the built in assets, sorted and without repeats.
+func (a *Assets) List(dir string) []string {
+	var names []string
+	if entries, err := fs.ReadDir(embeddedAssets, path.Join("assets", dir)); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				names = append(names, path.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	if a.Override != "" {
+		var overrides = filepath.Join(a.Override, filepath.FromSlash(dir))
+		var entries, err = os.ReadDir(overrides)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			rl.TraceLog(rl.LogError, "ASSETS: Failed to read override directory %s: %s", overrides, err.Error())
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				names = append(names, path.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func (a *Assets) LoadSound(name string) (rl.Sound, bool) {
+	return loadAsset(a, name, func(contents []byte) (rl.Sound, bool) {
+		if len(contents) == 0 {
+			return rl.Sound{}, false
+		}
+		var wave = rl.LoadWaveFromMemory(path.Ext(name), contents, int32(len(contents)))
+		if !rl.IsWaveReady(wave) {
+			return rl.Sound{}, false
+		}
+		defer rl.UnloadWave(wave)
+		var sound = rl.LoadSoundFromWave(wave)
+		return sound, rl.IsSoundReady(sound)
+	})
+}
+
+func (a *Assets) LoadMusic(name string) (rl.Music, bool) {
+	return loadAsset(a, name, func(contents []byte) (rl.Music, bool) {
+		if len(contents) == 0 {
+			return rl.Music{}, false
+		}
+		var music = rl.LoadMusicStreamFromMemory(path.Ext(name), contents, int32(len(contents)))
+		if !rl.IsMusicReady(music) {
+			return rl.Music{}, false
+		}
+		a.streamed = append(a.streamed, contents)
+		return music, true
+	})
+}
+
+func (a *Assets) LoadPresets(name string) (map[string]synth.Preset, bool) {
+	return loadAsset(a, name, func(contents []byte) (map[string]synth.Preset, bool) {
+		var presets, err = synth.LoadPresets(contents)
+		if err != nil {
+			rl.TraceLog(rl.LogError, "ASSETS: Invalid %s: %s", name, err.Error())
+			return nil, false
+		}
+		return presets, true
+	})
+}
+
+func (a *Assets) LoadSongs(name string) (*synth.Music, bool) {
+	return loadAsset(a, name, func(contents []byte) (*synth.Music, bool) {
+		var music, err = synth.LoadMusic(contents)
+		if err != nil {
+			rl.TraceLog(rl.LogError, "ASSETS: Invalid %s: %s", name, err.Error())
+			return nil, false
+		}
+		return music, true
+	})
+}
+
+func (a *Assets) LoadLevel(name string) (*sim.Level, bool) {
+	return loadAsset(a, name, func(contents []byte) (*sim.Level, bool) {
+		var level, err = sim.LoadLevel(contents)
+		if err != nil {
+			rl.TraceLog(rl.LogError, "ASSETS: Invalid %s: %s", name, err.Error())
+			return nil, false
+		}
+		return level, true
+	})
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"SpaceDroid/sim"
+	"SpaceDroid/synth"
+	"encoding/binary"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math/rand/v2"
+	"slices"
+)
+
+// The sound effects, by the name they are played with. The recorded ones are
+// loaded from files and the rest synthesized from presetsFile.
+const (
+	SoundSpaceShipDead = "space_ship_dead"
+	SoundWin           = "win"
+
+	SoundShoot           = "shoot"
+	SoundExplosionLarge  = "explosion_large"
+	SoundExplosionMedium = "explosion_medium"
+	SoundExplosionSmall  = "explosion_small"
+	SoundThrust          = "thrust"
+	SoundSaucerLarge     = "saucer_large"
+	SoundSaucerSmall     = "saucer_small"
+	SoundBeatLow         = "beat_low"
+	SoundBeatHigh        = "beat_high"
+	SoundHyperspace      = "hyperspace"
+)
+
+const presetsFile = "audio/sfx.json"
+
+// synthSounds are the presets the game plays, with how many voices each gets
+// and how much its pitch varies. Recordings are the override files that play
+// in place of the preset, first found first, so the names the sounds used to
+// ship as still work.
+var synthSounds = []struct {
+	name           string
+	voices         int
+	pitchVariation float32
+	recordings     []string
+}{
+	{SoundShoot, 6, 0.06, []string{"audio/shoot.wav"}},
+	{SoundExplosionLarge, 3, 0.1, []string{"audio/explosion_large.wav", "audio/asteroid_destroy.wav"}},
+	{SoundExplosionMedium, 4, 0.1, []string{"audio/explosion_medium.wav", "audio/asteroid_destroy.wav"}},
+	{SoundExplosionSmall, 4, 0.1, []string{"audio/explosion_small.wav", "audio/asteroid_destroy.wav"}},
+	{SoundHyperspace, 2, 0.04, []string{"audio/hyperspace.wav"}},
+	// the loops and the heartbeat keep a steady pitch
+	{SoundThrust, 1, 0, []string{"audio/thrust.wav"}},
+	{SoundSaucerLarge, 1, 0, []string{"audio/saucer_large.wav"}},
+	{SoundSaucerSmall, 1, 0, []string{"audio/saucer_small.wav"}},
+	{SoundBeatLow, 1, 0, []string{"audio/beat_low.wav"}},
+	{SoundBeatHigh, 1, 0, []string{"audio/beat_high.wav"}},
+}
+
+// maxPan is how far from the centre a sound at the screen edge is panned.
+const maxPan float32 = 0.35
+
+// soundVoices are the copies of one sound that can play over each other. The
+// first is the loaded sound and the rest are aliases sharing its samples.
+type soundVoices struct {
+	voices []rl.Sound
+	// next is the voice to take over once they are all playing
+	next int
+	// pitchVariation is how far each play is randomly detuned either way
+	pitchVariation float32
+	length         float32
+}
+
+// AudioManager plays sound effects by name, each on up to a fixed number of
+// voices so quick repeats overlap instead of cutting each other off. Without
+// an audio device, or for a sound that failed to load, playing does nothing.
+type AudioManager struct {
+	ready  bool
+	assets *Assets
+	sounds map[string]*soundVoices
+}
+
+func NewAudioManager(assets *Assets) *AudioManager {
+	var a = &AudioManager{ready: rl.IsAudioDeviceReady(), assets: assets, sounds: map[string]*soundVoices{}}
+	if !a.ready {
+		rl.TraceLog(rl.LogWarning, "AUDIO: No audio device, playing without sound")
+	}
+	return a
+}
+
+// Load reads a sound with room for voices copies of it playing at once.
+func (a *AudioManager) Load(name string, file string, voices int, pitchVariation float32) {
+	if !a.ready {
+		return
+	}
+
+	var sound, ok = a.assets.LoadSound(file)
+	if !ok {
+		rl.TraceLog(rl.LogWarning, "AUDIO: Failed to load %s, playing without it", file)
+		return
+	}
+	a.add(name, sound, voices, pitchVariation)
+}
+
+// LoadSynth renders each of synthSounds from the presets in presetsFile,
+// unless a recording of it is in the override assets.
+func (a *AudioManager) LoadSynth() {
+	if !a.ready {
+		return
+	}
+
+	var presets, ok = a.assets.LoadPresets(presetsFile)
+	if !ok {
+		rl.TraceLog(rl.LogWarning, "AUDIO: Failed to load %s, playing only overridden sounds", presetsFile)
+	}
+
+	for _, sound := range synthSounds {
+		// a recording that fails to decode falls back to the preset
+		if i := slices.IndexFunc(sound.recordings, a.assets.Overridden); i >= 0 {
+			if recording, ok := a.assets.LoadSound(sound.recordings[i]); ok {
+				a.add(sound.name, recording, sound.voices, sound.pitchVariation)
+				continue
+			}
+		}
+
+		var preset, ok = presets[sound.name]
+		if !ok {
+			rl.TraceLog(rl.LogWarning, "AUDIO: No preset for %s, playing without it", sound.name)
+			continue
+		}
+
+		var samples = preset.Render(synth.SampleRate)
+		var data = make([]byte, 2*len(samples))
+		for i, sample := range samples {
+			binary.LittleEndian.PutUint16(data[2*i:], uint16(sample))
+		}
+		// the wave points at Go memory, so it is copied into the sound but
+		// never unloaded
+		var wave = rl.NewWave(uint32(len(samples)), synth.SampleRate, 16, 1, data)
+		a.add(sound.name, rl.LoadSoundFromWave(wave), sound.voices, sound.pitchVariation)
+	}
+}
+
+func (a *AudioManager) add(name string, sound rl.Sound, voices int, pitchVariation float32) {
+	var loaded = &soundVoices{voices: []rl.Sound{sound}, pitchVariation: pitchVariation}
+	for range voices - 1 {
+		loaded.voices = append(loaded.voices, rl.LoadSoundAlias(sound))
+	}
+	if sound.Stream.SampleRate > 0 {
+		loaded.length = float32(sound.FrameCount) / float32(sound.Stream.SampleRate)
+	}
+	a.sounds[name] = loaded
+}
+
+func (a *AudioManager) Unload() {
+	for _, sound := range a.sounds {
+		// the aliases share the samples of the real sound, so only that one is
+		// unloaded. UnloadSoundAlias isn't in every binding, and unloading an
+		// alias as a sound would free the shared samples twice
+		for _, voice := range sound.voices {
+			rl.StopSound(voice)
+		}
+		rl.UnloadSound(sound.voices[0])
+	}
+	clear(a.sounds)
+}
+
+func (a *AudioManager) SetVolume(volume float32) {
+	for _, sound := range a.sounds {
+		for _, voice := range sound.voices {
+			rl.SetSoundVolume(voice, volume)
+		}
+	}
+}
+
+// Length is how many seconds a sound lasts, 0 if it isn't loaded.
+func (a *AudioManager) Length(name string) float32 {
+	if sound, ok := a.sounds[name]; ok {
+		return sound.length
+	}
+	return 0
+}
+
+// Play starts a sound panned to where x is across the play field, on a free
+// voice or else by cutting off the next voice in round-robin order.
+func (a *AudioManager) Play(name string, x float32) {
+	var sound, ok = a.sounds[name]
+	if !ok {
+		return
+	}
+
+	var voice = sound.voices[sound.next]
+	for i, candidate := range sound.voices {
+		if !rl.IsSoundPlaying(candidate) {
+			voice = candidate
+			sound.next = i
+			break
+		}
+	}
+	sound.next = (sound.next + 1) % len(sound.voices)
+
+	setPan(voice, x)
+	rl.SetSoundPitch(voice, 1+sound.pitchVariation*(rand.Float32()*2-1))
+	rl.PlaySound(voice)
+}
+
+// Loop keeps a sound going while on, starting it again each time it ends and
+// following x as it moves, and stops it once on goes false.
+func (a *AudioManager) Loop(name string, on bool, x float32) {
+	var sound, ok = a.sounds[name]
+	if !ok {
+		return
+	}
+
+	var voice = sound.voices[0]
+	if !on {
+		if rl.IsSoundPlaying(voice) {
+			rl.StopSound(voice)
+		}
+		return
+	}
+
+	setPan(voice, x)
+	if !rl.IsSoundPlaying(voice) {
+		rl.PlaySound(voice)
+	}
+}
+
+func setPan(voice rl.Sound, x float32) {
+	// raylib pans fully left at 1 and fully right at 0
+	var across = max(0, min(1, x/screenWidth))
+	rl.SetSoundPan(voice, 0.5+maxPan*(1-2*across))
+}
+
+// ExplosionSound is the explosion that goes with breaking up an asteroid of
+// size.
+func ExplosionSound(size sim.AsteroidSize) string {
+	switch size {
+	case sim.Large:
+		return SoundExplosionLarge
+	case sim.Medium:
+		return SoundExplosionMedium
+	}
+	return SoundExplosionSmall
+}
+
+// PlayGameSounds keeps the sounds that follow what the game is doing, rather
+// than something that just happened, going for one step.
+func PlayGameSounds(data *GameData) {
+	var game = data.Game
+	var running = !game.Paused && !game.GameOver
+
+	var thrusting = running && game.Player != nil && game.Player.Thrusting
+	var shipX float32 = screenWidth / 2
+	if game.Player != nil {
+		shipX = game.Player.Position.X
+	}
+	data.Audio.Loop(SoundThrust, thrusting, shipX)
+
+	var saucer = game.Saucer
+	var saucerX float32 = screenWidth / 2
+	if saucer != nil {
+		saucerX = saucer.Position.X
+	}
+	data.Audio.Loop(SoundSaucerLarge, running && saucer != nil && saucer.Size == sim.LargeSaucer, saucerX)
+	data.Audio.Loop(SoundSaucerSmall, running && saucer != nil && saucer.Size == sim.SmallSaucer, saucerX)
+
+	if !running || game.Asteroids.Len() == 0 {
+		data.Heartbeat.Reset()
+		return
+	}
+	if data.Heartbeat.Step(sim.TickDuration, game.WaveProgress()) {
+		var beat = SoundBeatLow
+		if data.Heartbeat.High {
+			beat = SoundBeatHigh
+		}
+		data.Audio.Play(beat, screenWidth/2)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"SpaceDroid/sim"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math"
+)
+
+// MotionLevel is the accessibility setting for how much the camera moves on
+// impacts.
+type MotionLevel string
+
+const (
+	MotionFull    MotionLevel = "full"
+	MotionReduced MotionLevel = "reduced"
+	MotionOff     MotionLevel = "off"
+)
+
+var MotionLevels = []MotionLevel{MotionFull, MotionReduced, MotionOff}
+
+func (m MotionLevel) Name() string {
+	switch m {
+	case MotionFull:
+		return "Full"
+	case MotionReduced:
+		return "Reduced"
+	case MotionOff:
+		return "Off"
+	}
+	return "Unknown"
+}
+
+// Scale is what every camera effect is multiplied by.
+func (m MotionLevel) Scale() float32 {
+	switch m {
+	case MotionFull:
+		return 1
+	case MotionReduced:
+		return 0.35
+	}
+	return 0
+}
+
+// maxShakeOffset is in world units and maxShakeAngle in degrees, how far the
+// view moves at full trauma.
+const maxShakeOffset float32 = 12
+const maxShakeAngle float32 = 2
+
+// traumaDecay and punchDecay are how much trauma and zoom punch wear off per
+// second.
+const traumaDecay float32 = 1.5
+const punchDecay float32 = 0.4
+
+// Impact is how hard something hit: Trauma adds to the shake, HitStop freezes
+// the game for that many seconds and Punch zooms in by that fraction.
+type Impact struct {
+	Trauma  float32
+	HitStop float32
+	Punch   float32
+}
+
+// ImpactOf is how hard an event hits, bigger things hitting harder.
+func ImpactOf(event sim.Event) Impact {
+	switch event.Type {
+	case sim.AsteroidDestroyed:
+		switch event.Size {
+		case sim.Large:
+			return Impact{Trauma: 0.35, HitStop: 0.04, Punch: 0.03}
+		case sim.Medium:
+			return Impact{Trauma: 0.15, Punch: 0.01}
+		}
+		return Impact{Trauma: 0.05}
+	case sim.SaucerDestroyed:
+		return Impact{Trauma: 0.3, HitStop: 0.05, Punch: 0.03}
+	case sim.ShipDied:
+		return Impact{Trauma: 0.8, HitStop: 0.12, Punch: 0.08}
+	}
+	return Impact{}
+}
+
+// CameraEffects shakes, freezes and zooms the view on impacts. Shake follows
+// trauma squared, so small hits barely move the view and big ones pile up.
+type CameraEffects struct {
+	trauma  float32
+	hitStop float32
+	punch   float32
+	// time drives the shake so it moves smoothly rather than jumping about
+	time float32
+}
+
+func (c *CameraEffects) Clear() {
+	*c = CameraEffects{}
+}
+
+// Trigger adds the impacts of a step's events, scaled by the motion setting.
+func (c *CameraEffects) Trigger(events []sim.Event, motion MotionLevel) {
+	var scale = motion.Scale()
+	for _, event := range events {
+		var impact = ImpactOf(event)
+		c.trauma = min(1, c.trauma+impact.Trauma*scale)
+		c.hitStop = max(c.hitStop, impact.HitStop*scale)
+		c.punch = max(c.punch, impact.Punch*scale)
+	}
+}
+
+// Update wears the effects off over frameTime seconds and returns how much of
+// that time the game should run for, which is none during a hit-stop.
+func (c *CameraEffects) Update(frameTime float32) float32 {
+	c.time += frameTime
+	c.trauma = max(0, c.trauma-traumaDecay*frameTime)
+	c.punch = max(0, c.punch-punchDecay*frameTime)
+
+	var stopped = min(c.hitStop, frameTime)
+	c.hitStop -= stopped
+	return frameTime - stopped
+}
+
+// Apply moves a camera already set up to show the play field, shaking and
+// zooming it about the middle of the screen.
+func (c *CameraEffects) Apply(camera *rl.Camera2D) {
+	var shake = c.trauma * c.trauma
+	var center = rl.NewVector2(screenWidth/2, screenHeight/2)
+	var offset = rl.NewVector2(
+		maxShakeOffset*shake*smoothNoise(c.time, 0),
+		maxShakeOffset*shake*smoothNoise(c.time, 1),
+	)
+
+	camera.Target = center
+	camera.Offset = rl.Vector2Add(camera.Offset, rl.Vector2Scale(rl.Vector2Add(center, offset), camera.Zoom))
+	camera.Rotation = maxShakeAngle * shake * smoothNoise(c.time, 2)
+	camera.Zoom *= 1 + c.punch
+}
+
+// smoothNoise wobbles between -1 and 1 over time, differently for each seed.
+func smoothNoise(time float32, seed float64) float32 {
+	var t = float64(time)
+	return float32(math.Sin(t*31+seed*7)*0.6 + math.Sin(t*53+seed*13)*0.4)
+}
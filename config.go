@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigPath returns where a file with the given name lives in the game's
+// directory under the user config directory, creating the directory if needed.
+func ConfigPath(name string) (string, error) {
+	var dir, err = os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "SpaceDroid")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it
+// into place, so a crash part way through never leaves a truncated file.
+func WriteFileAtomic(path string, data []byte) error {
+	var file, err = os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math"
+	"slices"
+	"strings"
+)
+
+type Action int32
+
+const (
+	ActionThrust Action = iota
+	ActionRotateLeft
+	ActionRotateRight
+	ActionFire
+	ActionHyperspace
+	ActionPause
+	ActionRestart
+	ActionMenuUp
+	ActionMenuDown
+	ActionMenuLeft
+	ActionMenuRight
+	ActionMenuConfirm
+	ActionMenuBack
+	actionCount
+)
+
+func (a Action) Name() string {
+	switch a {
+	case ActionThrust:
+		return "Thrust"
+	case ActionRotateLeft:
+		return "Rotate Left"
+	case ActionRotateRight:
+		return "Rotate Right"
+	case ActionFire:
+		return "Fire"
+	case ActionHyperspace:
+		return "Hyperspace"
+	case ActionPause:
+		return "Pause"
+	case ActionRestart:
+		return "Restart"
+	case ActionMenuUp:
+		return "Menu Up"
+	case ActionMenuDown:
+		return "Menu Down"
+	case ActionMenuLeft:
+		return "Menu Left"
+	case ActionMenuRight:
+		return "Menu Right"
+	case ActionMenuConfirm:
+		return "Menu Confirm"
+	case ActionMenuBack:
+		return "Menu Back"
+	}
+
+	return "Unknown"
+}
+
+// IsMenuAction splits the actions into two groups. A key can be bound to one
+// gameplay and one menu action, but not to two actions of the same group.
+func (a Action) IsMenuAction() bool {
+	return a >= ActionMenuUp
+}
+
+type BindingKind string
+
+const (
+	BindKey           BindingKind = "key"
+	BindGamepadButton BindingKind = "button"
+	BindGamepadAxis   BindingKind = "axis"
+)
+
+type Binding struct {
+	Kind BindingKind `json:"kind"`
+	Code int32       `json:"code"`
+	// Direction is -1 or 1 for axis bindings, the way the stick must be pushed
+	Direction int32 `json:"direction,omitempty"`
+}
+
+func Key(key int32) Binding {
+	return Binding{Kind: BindKey, Code: key}
+}
+
+func GamepadButton(button int32) Binding {
+	return Binding{Kind: BindGamepadButton, Code: button}
+}
+
+func GamepadAxis(axis int32, direction int32) Binding {
+	return Binding{Kind: BindGamepadAxis, Code: axis, Direction: direction}
+}
+
+func (b Binding) Valid() bool {
+	switch b.Kind {
+	case BindKey:
+		return b.Code > 0
+	case BindGamepadButton:
+		return b.Code > rl.GamepadButtonUnknown && b.Code <= rl.GamepadButtonRightThumb
+	case BindGamepadAxis:
+		return b.Code >= rl.GamepadAxisLeftX && b.Code <= rl.GamepadAxisRightTrigger && (b.Direction == -1 || b.Direction == 1)
+	}
+	return false
+}
+
+// gamepad is the only controller read, the game being single player.
+const gamepad = 0
+
+// axisDeadzone is how far a stick must move before it counts as pushed.
+const axisDeadzone = 0.25
+
+// Value is how strongly the binding is held, from 0 to 1.
+func (b Binding) Value() float32 {
+	switch b.Kind {
+	case BindKey:
+		if rl.IsKeyDown(b.Code) {
+			return 1
+		}
+	case BindGamepadButton:
+		if rl.IsGamepadAvailable(gamepad) && rl.IsGamepadButtonDown(gamepad, b.Code) {
+			return 1
+		}
+	case BindGamepadAxis:
+		if rl.IsGamepadAvailable(gamepad) {
+			var value = rl.GetGamepadAxisMovement(gamepad, b.Code) * float32(b.Direction)
+			if value > axisDeadzone {
+				return min(1, (value-axisDeadzone)/(1-axisDeadzone))
+			}
+		}
+	}
+	return 0
+}
+
+func (b Binding) Name() string {
+	switch b.Kind {
+	case BindKey:
+		return keyName(b.Code)
+	case BindGamepadButton:
+		return gamepadButtonName(b.Code)
+	case BindGamepadAxis:
+		return gamepadAxisName(b.Code, b.Direction)
+	}
+	return "?"
+}
+
+func keyName(key int32) string {
+	if (key >= rl.KeyA && key <= rl.KeyZ) || (key >= rl.KeyZero && key <= rl.KeyNine) {
+		return string(rune(key))
+	}
+	if key >= rl.KeyF1 && key <= rl.KeyF12 {
+		return fmt.Sprintf("F%d", key-rl.KeyF1+1)
+	}
+
+	switch key {
+	case rl.KeySpace:
+		return "Space"
+	case rl.KeyEnter:
+		return "Enter"
+	case rl.KeyEscape:
+		return "Escape"
+	case rl.KeyBackspace:
+		return "Backspace"
+	case rl.KeyTab:
+		return "Tab"
+	case rl.KeyUp:
+		return "Up"
+	case rl.KeyDown:
+		return "Down"
+	case rl.KeyLeft:
+		return "Left"
+	case rl.KeyRight:
+		return "Right"
+	case rl.KeyLeftShift:
+		return "Left Shift"
+	case rl.KeyRightShift:
+		return "Right Shift"
+	case rl.KeyLeftControl:
+		return "Left Ctrl"
+	case rl.KeyRightControl:
+		return "Right Ctrl"
+	case rl.KeyLeftAlt:
+		return "Left Alt"
+	case rl.KeyRightAlt:
+		return "Right Alt"
+	case rl.KeyComma:
+		return ","
+	case rl.KeyPeriod:
+		return "."
+	case rl.KeySlash:
+		return "/"
+	case rl.KeySemicolon:
+		return ";"
+	}
+
+	return fmt.Sprintf("Key %d", key)
+}
+
+func gamepadButtonName(button int32) string {
+	switch button {
+	case rl.GamepadButtonLeftFaceUp:
+		return "D-Pad Up"
+	case rl.GamepadButtonLeftFaceRight:
+		return "D-Pad Right"
+	case rl.GamepadButtonLeftFaceDown:
+		return "D-Pad Down"
+	case rl.GamepadButtonLeftFaceLeft:
+		return "D-Pad Left"
+	case rl.GamepadButtonRightFaceUp:
+		return "Pad Y"
+	case rl.GamepadButtonRightFaceRight:
+		return "Pad B"
+	case rl.GamepadButtonRightFaceDown:
+		return "Pad A"
+	case rl.GamepadButtonRightFaceLeft:
+		return "Pad X"
+	case rl.GamepadButtonLeftTrigger1:
+		return "Pad LB"
+	case rl.GamepadButtonLeftTrigger2:
+		return "Pad LT"
+	case rl.GamepadButtonRightTrigger1:
+		return "Pad RB"
+	case rl.GamepadButtonRightTrigger2:
+		return "Pad RT"
+	case rl.GamepadButtonMiddleLeft:
+		return "Pad Back"
+	case rl.GamepadButtonMiddle:
+		return "Pad Home"
+	case rl.GamepadButtonMiddleRight:
+		return "Pad Start"
+	case rl.GamepadButtonLeftThumb:
+		return "Left Stick Press"
+	case rl.GamepadButtonRightThumb:
+		return "Right Stick Press"
+	}
+
+	return fmt.Sprintf("Pad Button %d", button)
+}
+
+func gamepadAxisName(axis int32, direction int32) string {
+	var negative, positive string
+	switch axis {
+	case rl.GamepadAxisLeftX:
+		return "Left Stick " + pick(direction, "Left", "Right")
+	case rl.GamepadAxisLeftY:
+		return "Left Stick " + pick(direction, "Up", "Down")
+	case rl.GamepadAxisRightX:
+		return "Right Stick " + pick(direction, "Left", "Right")
+	case rl.GamepadAxisRightY:
+		return "Right Stick " + pick(direction, "Up", "Down")
+	case rl.GamepadAxisLeftTrigger:
+		negative, positive = "Pad LT Released", "Pad LT"
+	case rl.GamepadAxisRightTrigger:
+		negative, positive = "Pad RT Released", "Pad RT"
+	default:
+		return fmt.Sprintf("Pad Axis %d %+d", axis, direction)
+	}
+	return pick(direction, negative, positive)
+}
+
+func pick(direction int32, negative string, positive string) string {
+	if direction < 0 {
+		return negative
+	}
+	return positive
+}
+
+// Controls holds the bindings of every action.
+type Controls [actionCount][]Binding
+
+func DefaultControls() Controls {
+	var controls Controls
+	controls[ActionThrust] = []Binding{Key(rl.KeyW), Key(rl.KeyUp), GamepadButton(rl.GamepadButtonRightTrigger2), GamepadAxis(rl.GamepadAxisRightTrigger, 1)}
+	controls[ActionRotateLeft] = []Binding{Key(rl.KeyA), Key(rl.KeyLeft), GamepadButton(rl.GamepadButtonLeftFaceLeft), GamepadAxis(rl.GamepadAxisLeftX, -1)}
+	controls[ActionRotateRight] = []Binding{Key(rl.KeyD), Key(rl.KeyRight), GamepadButton(rl.GamepadButtonLeftFaceRight), GamepadAxis(rl.GamepadAxisLeftX, 1)}
+	controls[ActionFire] = []Binding{Key(rl.KeySpace), GamepadButton(rl.GamepadButtonRightFaceDown)}
+	controls[ActionHyperspace] = []Binding{Key(rl.KeyS), Key(rl.KeyDown), GamepadButton(rl.GamepadButtonRightFaceLeft)}
+	controls[ActionPause] = []Binding{Key(rl.KeyP), GamepadButton(rl.GamepadButtonMiddleRight)}
+	controls[ActionRestart] = []Binding{Key(rl.KeyR), GamepadButton(rl.GamepadButtonMiddleLeft)}
+	controls[ActionMenuUp] = []Binding{Key(rl.KeyUp), Key(rl.KeyW), GamepadButton(rl.GamepadButtonLeftFaceUp), GamepadAxis(rl.GamepadAxisLeftY, -1)}
+	controls[ActionMenuDown] = []Binding{Key(rl.KeyDown), Key(rl.KeyS), GamepadButton(rl.GamepadButtonLeftFaceDown), GamepadAxis(rl.GamepadAxisLeftY, 1)}
+	controls[ActionMenuLeft] = []Binding{Key(rl.KeyLeft), Key(rl.KeyA), GamepadButton(rl.GamepadButtonLeftFaceLeft), GamepadAxis(rl.GamepadAxisLeftX, -1)}
+	controls[ActionMenuRight] = []Binding{Key(rl.KeyRight), Key(rl.KeyD), GamepadButton(rl.GamepadButtonLeftFaceRight), GamepadAxis(rl.GamepadAxisLeftX, 1)}
+	controls[ActionMenuConfirm] = []Binding{Key(rl.KeyEnter), Key(rl.KeySpace), GamepadButton(rl.GamepadButtonRightFaceDown)}
+	controls[ActionMenuBack] = []Binding{Key(rl.KeyEscape), GamepadButton(rl.GamepadButtonRightFaceRight)}
+	return controls
+}
+
+// Describe lists the bindings of an action for display, e.g. "W / Up".
+func (c *Controls) Describe(action Action) string {
+	var names = make([]string, 0, len(c[action]))
+	for _, binding := range c[action] {
+		names = append(names, binding.Name())
+	}
+	if len(names) == 0 {
+		return "Unbound"
+	}
+	return strings.Join(names, " / ")
+}
+
+// Primary names the first binding of an action for short prompts.
+func (c *Controls) Primary(action Action) string {
+	if len(c[action]) == 0 {
+		return "Unbound"
+	}
+	return c[action][0].Name()
+}
+
+// Bind adds a binding to an action, taking it away from any other action in
+// the same group so one key never triggers two gameplay or two menu actions.
+func (c *Controls) Bind(action Action, binding Binding) {
+	for other := range actionCount {
+		if other.IsMenuAction() == action.IsMenuAction() {
+			c[other] = slices.DeleteFunc(c[other], func(b Binding) bool { return b == binding })
+		}
+	}
+	c[action] = append(c[action], binding)
+}
+
+// MarshalJSON stores the bindings keyed by action name so the file survives
+// actions being added or reordered.
+func (c Controls) MarshalJSON() ([]byte, error) {
+	var named = map[string][]Binding{}
+	for action := range actionCount {
+		named[action.Name()] = c[action]
+		if named[action.Name()] == nil {
+			named[action.Name()] = []Binding{}
+		}
+	}
+	return json.Marshal(named)
+}
+
+// UnmarshalJSON keeps the default bindings for any action missing from the
+// file and drops bindings that don't make sense.
+func (c *Controls) UnmarshalJSON(contents []byte) error {
+	var named map[string][]Binding
+	if err := json.Unmarshal(contents, &named); err != nil {
+		return err
+	}
+
+	*c = DefaultControls()
+	for action := range actionCount {
+		var bindings, ok = named[action.Name()]
+		if !ok {
+			continue
+		}
+		c[action] = slices.DeleteFunc(bindings, func(b Binding) bool {
+			if !b.Valid() {
+				rl.TraceLog(rl.LogWarning, "SETTINGS: Dropping invalid %s binding %s %d", action.Name(), b.Kind, b.Code)
+				return true
+			}
+			return false
+		})
+	}
+	return nil
+}
+
+// Validate restores the defaults of any menu action left without a binding,
+// otherwise the player could lock themselves out of the menus.
+func (c *Controls) Validate() {
+	var defaults = DefaultControls()
+	for _, action := range []Action{ActionMenuUp, ActionMenuDown, ActionMenuConfirm, ActionMenuBack} {
+		if len(c[action]) == 0 {
+			rl.TraceLog(rl.LogWarning, "SETTINGS: %s has no bindings, restoring defaults", action.Name())
+			c[action] = defaults[action]
+		}
+	}
+}
+
+// InputState is the per frame view of the controls. Update must be called
+// once at the start of every frame.
+type InputState struct {
+	down     [actionCount]bool
+	prevDown [actionCount]bool
+	digital  [actionCount]bool
+	analog   [actionCount]float32
+}
+
+func (i *InputState) Update(controls *Controls) {
+	i.prevDown = i.down
+	for action := range actionCount {
+		i.down[action] = false
+		i.digital[action] = false
+		i.analog[action] = 0
+		for _, binding := range controls[action] {
+			var value = binding.Value()
+			if value <= 0 {
+				continue
+			}
+			i.down[action] = true
+			if binding.Kind == BindGamepadAxis {
+				i.analog[action] = max(i.analog[action], value)
+			} else {
+				i.digital[action] = true
+			}
+		}
+	}
+}
+
+func (i *InputState) Down(action Action) bool {
+	return i.down[action]
+}
+
+// DigitalDown ignores stick bindings, whose strength is reported separately.
+func (i *InputState) DigitalDown(action Action) bool {
+	return i.digital[action]
+}
+
+func (i *InputState) Pressed(action Action) bool {
+	return i.down[action] && !i.prevDown[action]
+}
+
+// Turn is the analog rotation from the sticks, quantised for sim.Input.
+func (i *InputState) Turn() int8 {
+	var turn = i.analog[ActionRotateRight] - i.analog[ActionRotateLeft]
+	return int8(math.Round(float64(turn * 127)))
+}
+
+// Capture waits for the next key, button or stick movement to bind it.
+type Capture struct {
+	Active bool
+	Action Action
+	// axesArmed is set once every stick is back near the centre, so the stick
+	// that navigated to the entry isn't captured straight away
+	axesArmed bool
+}
+
+func (c *Capture) Start(action Action) {
+	*c = Capture{Active: true, Action: action}
+	// drop the keys pressed this frame, including the one that started us
+	for rl.GetKeyPressed() != 0 {
+	}
+}
+
+// Poll returns the binding that was pressed, if any. Escape cancels.
+func (c *Capture) Poll() (Binding, bool) {
+	for key := rl.GetKeyPressed(); key != 0; key = rl.GetKeyPressed() {
+		if key == rl.KeyEscape {
+			c.Active = false
+			return Binding{}, false
+		}
+		return Key(key), true
+	}
+
+	if !rl.IsGamepadAvailable(gamepad) {
+		return Binding{}, false
+	}
+
+	for button := int32(rl.GamepadButtonLeftFaceUp); button <= rl.GamepadButtonRightThumb; button++ {
+		if rl.IsGamepadButtonPressed(gamepad, button) {
+			return GamepadButton(button), true
+		}
+	}
+
+	var idle = true
+	for axis := int32(rl.GamepadAxisLeftX); axis <= rl.GamepadAxisRightY; axis++ {
+		var value = rl.GetGamepadAxisMovement(gamepad, axis)
+		if math.Abs(float64(value)) > 0.3 {
+			idle = false
+		}
+		if c.axesArmed && math.Abs(float64(value)) > 0.6 {
+			return GamepadAxis(axis, int32(math.Copysign(1, float64(value)))), true
+		}
+	}
+	if idle {
+		c.axesArmed = true
+	}
+
+	return Binding{}, false
+}
+
+func ProcessControlsState(data *GameData) {
+	DrawTextCenter("CONTROLS", 20, 42, rl.Green)
+
+	var controls = &data.Settings.Controls
+	var capture = &data.Capture
+	var count = int(actionCount) + 2
+
+	var y int32 = 75
+	for action := range actionCount {
+		var color = rl.RayWhite
+		if int(action) == data.ControlsIndex {
+			color = rl.Gold
+		}
+		var bindings = controls.Describe(action)
+		if capture.Active && capture.Action == action {
+			bindings = "Press a key or button... (Escape to cancel)"
+		}
+		rl.DrawText(action.Name(), 60, y, 16, color)
+		rl.DrawText(bindings, 220, y, 16, color)
+		y += 22
+	}
+
+	DrawMenuItem("Reset to Defaults", float32(y+8), data.ControlsIndex == int(actionCount))
+	DrawMenuItem("Back", float32(y+36), data.ControlsIndex == int(actionCount)+1)
+	DrawTextCenter("Confirm adds a binding, Backspace clears an action", screenHeight-20, 14, rl.Gray)
+
+	if capture.Active {
+		if binding, ok := capture.Poll(); ok {
+			controls.Bind(capture.Action, binding)
+			controls.Validate()
+			capture.Active = false
+		}
+		return
+	}
+
+	var input = &data.Input
+	if input.Pressed(ActionMenuDown) {
+		data.ControlsIndex = (data.ControlsIndex + 1) % count
+	}
+	if input.Pressed(ActionMenuUp) {
+		data.ControlsIndex = (data.ControlsIndex + count - 1) % count
+	}
+
+	if data.ControlsIndex < int(actionCount) && rl.IsKeyPressed(rl.KeyBackspace) {
+		controls[data.ControlsIndex] = nil
+		controls.Validate()
+	}
+
+	if input.Pressed(ActionMenuConfirm) {
+		switch data.ControlsIndex {
+		case int(actionCount):
+			*controls = DefaultControls()
+		case int(actionCount) + 1:
+			BackToOptions(data)
+		default:
+			capture.Start(Action(data.ControlsIndex))
+		}
+	} else if input.Pressed(ActionMenuBack) {
+		BackToOptions(data)
+	}
+}
+
+func BackToOptions(data *GameData) {
+	if err := data.Settings.Save(); err != nil {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Failed to save settings: %s", err.Error())
+	}
+	data.GameState = Options
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"SpaceDroid/scores"
+	"encoding/json"
+	"errors"
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+const highScoresFile = "highscores.json"
+const initialsLength = 3
+
+const gameModeClassic = "Classic"
+const gameModePhysics = "Physics"
+
+type NameEntry struct {
+	Initials [initialsLength]byte
+	Cursor   int
+	Score    scores.HighScore
+}
+
+// LoadHighScores never fails: a missing or unreadable table starts empty.
+func LoadHighScores() *scores.Table {
+	var path, err = ConfigPath(highScoresFile)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "SCORES: Failed to find config directory: %s", err.Error())
+		return &scores.Table{}
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &scores.Table{}
+	}
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "SCORES: Failed to read %s: %s", path, err.Error())
+		return &scores.Table{}
+	}
+
+	table, err := scores.Decode(contents)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "SCORES: Ignoring malformed %s: %s", path, err.Error())
+		return &scores.Table{}
+	}
+	return table
+}
+
+func SaveHighScores(table *scores.Table) error {
+	var path, err = ConfigPath(highScoresFile)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(table, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(path, contents)
+}
+
+func StartNameEntry(data *GameData) {
+	var game = data.Game
+	var mode = gameModeClassic
+	if game.Options.AsteroidPhysics {
+		mode = gameModePhysics
+	}
+	data.NameEntry = NameEntry{
+		Initials: [initialsLength]byte{'A', 'A', 'A'},
+		Score:    scores.HighScore{Score: game.Score, Wave: game.Wave, Mode: mode, Level: game.Level.Name},
+	}
+	data.CameraEffects.Clear()
+	data.GameState = EnterName
+}
+
+func ProcessNameEntryState(data *GameData) {
+	var entry = &data.NameEntry
+
+	DrawTextCenter("NEW HIGH SCORE", 70, 42, rl.Gold)
+	DrawTextCenter(fmt.Sprintf("%d", entry.Score.Score), 130, 20, rl.RayWhite)
+	DrawTextCenter("Enter your initials", 180, 18, rl.White)
+
+	const letterWidth = 40
+	var x = screenWidth/2 - letterWidth*initialsLength/2
+	for i, letter := range entry.Initials {
+		var px = x + float32(i*letterWidth)
+		rl.DrawText(string(letter), int32(px+10), 230, 40, rl.RayWhite)
+		if i == entry.Cursor {
+			rl.DrawLineEx(rl.NewVector2(px+5, 275), rl.NewVector2(px+letterWidth-5, 275), 2, rl.RayWhite)
+		}
+	}
+
+	DrawMenuItem("Done", 340, entry.Cursor == initialsLength)
+
+	// typed letters go straight in, and since letters may also be bound to
+	// menu actions those are ignored on a frame where a letter was taken.
+	// Anything else, such as a space to confirm Done, still counts as input
+	var typed = false
+	for char := rl.GetCharPressed(); char != 0; char = rl.GetCharPressed() {
+		var letter = strings.ToUpper(string(char))
+		if len(letter) == 1 && letter[0] >= 'A' && letter[0] <= 'Z' && entry.Cursor < initialsLength {
+			entry.Initials[entry.Cursor] = letter[0]
+			entry.Cursor++
+			typed = true
+		}
+	}
+	if typed {
+		return
+	}
+
+	var input = &data.Input
+	if entry.Cursor < initialsLength {
+		if input.Pressed(ActionMenuUp) {
+			entry.Initials[entry.Cursor] = 'A' + (entry.Initials[entry.Cursor]-'A'+1)%26
+		}
+		if input.Pressed(ActionMenuDown) {
+			entry.Initials[entry.Cursor] = 'A' + (entry.Initials[entry.Cursor]-'A'+25)%26
+		}
+	}
+
+	if input.Pressed(ActionMenuRight) && entry.Cursor < initialsLength {
+		entry.Cursor++
+	}
+	if (input.Pressed(ActionMenuLeft) || rl.IsKeyPressed(rl.KeyBackspace)) && entry.Cursor > 0 {
+		entry.Cursor--
+	}
+
+	if input.Pressed(ActionMenuConfirm) {
+		entry.Score.Initials = string(entry.Initials[:])
+		entry.Score.Date = time.Now()
+		data.HighlightRank = data.HighScores.Insert(entry.Score)
+		data.HighScoresLevel = data.LevelIndex
+		if err := SaveHighScores(data.HighScores); err != nil {
+			rl.TraceLog(rl.LogWarning, "SCORES: Failed to save high scores: %s", err.Error())
+		}
+		data.GameState = HighScores
+	}
+}
+
+func ProcessHighScoresState(data *GameData) {
+	DrawTextCenter("HIGH SCORES", 20, 42, rl.Green)
+
+	var count = int32(len(data.Levels))
+	var level = data.Levels[data.HighScoresLevel]
+	if count > 1 {
+		DrawTextCenter(fmt.Sprintf("< %s >", level.Name), 70, 18, rl.Gold)
+	} else {
+		DrawTextCenter(level.Name, 70, 18, rl.Gold)
+	}
+
+	var scores = data.HighScores.Level(level.Name)
+	if len(scores) == 0 {
+		DrawTextCenter("No scores yet", 200, 18, rl.White)
+	}
+
+	var y int32 = 100
+	for i, score := range scores {
+		var color = rl.RayWhite
+		if i == data.HighlightRank {
+			color = rl.Gold
+		}
+		rl.DrawText(fmt.Sprintf("%2d. %s", i+1, score.Initials), 150, y, 18, color)
+		rl.DrawText(fmt.Sprintf("%d", score.Score), 290, y, 18, color)
+		rl.DrawText(fmt.Sprintf("WAVE %d", score.Wave), 390, y, 18, color)
+		rl.DrawText(score.Mode, 500, y, 18, color)
+		rl.DrawText(score.Date.Format("2006-01-02"), 580, y, 18, color)
+		y += 26
+	}
+
+	DrawMenuItem("Back", 400, true)
+
+	if data.Input.Pressed(ActionMenuRight) {
+		data.HighScoresLevel = (data.HighScoresLevel + 1) % count
+		data.HighlightRank = -1
+	}
+	if data.Input.Pressed(ActionMenuLeft) {
+		data.HighScoresLevel = (data.HighScoresLevel + count - 1) % count
+		data.HighlightRank = -1
+	}
+
+	if data.Input.Pressed(ActionMenuConfirm) || data.Input.Pressed(ActionMenuBack) {
+		data.HighlightRank = -1
+		data.GameState = Menu
+	}
+}
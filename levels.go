@@ -0,0 +1,77 @@
+package main
+
+import (
+	"SpaceDroid/sim"
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"path"
+)
+
+const levelsDir = "levels"
+
+// maxVisibleLevels is how many levels fit on the level select screen, which
+// scrolls to keep the selected one in view.
+const maxVisibleLevels = 7
+
+// LoadLevels lists the built in classic level followed by every valid level
+// file found in the assets. Invalid files are logged and left out.
+func LoadLevels(assets *Assets) []*sim.Level {
+	var levels = []*sim.Level{&sim.ClassicLevel}
+	for _, name := range assets.List(levelsDir) {
+		if path.Ext(name) != ".json" {
+			continue
+		}
+		if level, ok := assets.LoadLevel(name); ok {
+			levels = append(levels, level)
+		}
+	}
+	rl.TraceLog(rl.LogInfo, "LEVELS: Found %d levels", len(levels))
+	return levels
+}
+
+// Goal describes what it takes to win a level.
+func Goal(level *sim.Level) string {
+	var win = level.Win
+	switch {
+	case win.Waves > 0 && win.Score > 0:
+		return fmt.Sprintf("Clear %d waves or score %d points", win.Waves, win.Score)
+	case win.Waves > 0:
+		return fmt.Sprintf("Clear %d waves", win.Waves)
+	case win.Score > 0:
+		return fmt.Sprintf("Score %d points", win.Score)
+	}
+	return "Survive as long as you can"
+}
+
+func ProcessLevelSelectState(data *GameData) {
+	DrawTextCenter("LEVELS", 40, 42, rl.Green)
+
+	var count = int32(len(data.Levels))
+	var first = max(0, min(data.LevelIndex-maxVisibleLevels/2, count-maxVisibleLevels))
+	var y float32 = 110
+	for i := first; i < min(count, first+maxVisibleLevels); i++ {
+		DrawMenuItem(data.Levels[i].Name, y, data.LevelIndex == i)
+		y += 28
+	}
+
+	var level = data.Levels[data.LevelIndex]
+	DrawTextCenter(level.Description, 330, 16, rl.White)
+	DrawTextCenter(Goal(level), 355, 16, rl.Gold)
+	DrawTextCenter(fmt.Sprintf("Levels are read from the %s folder of the assets", levelsDir), 410, 14, rl.Gray)
+
+	var input = &data.Input
+	if input.Pressed(ActionMenuDown) {
+		data.LevelIndex = (data.LevelIndex + 1) % count
+	}
+	if input.Pressed(ActionMenuUp) {
+		data.LevelIndex = (data.LevelIndex + count - 1) % count
+	}
+
+	if input.Pressed(ActionMenuConfirm) {
+		NewGame(data)
+		data.GameState = Game
+	}
+	if input.Pressed(ActionMenuBack) {
+		data.GameState = Menu
+	}
+}
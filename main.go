@@ -1,9 +1,13 @@
 package main
 
 import (
+	"SpaceDroid/scores"
+	"SpaceDroid/sim"
+	"SpaceDroid/synth"
+	"flag"
 	"fmt"
 	rl "github.com/gen2brain/raylib-go/raylib"
-	"math"
+	"math/rand/v2"
 )
 
 type State int32
@@ -12,78 +16,141 @@ const (
 	Menu State = iota
 	Instructions
 	Game
+	Replay
+	EnterName
+	HighScores
+	Options
+	ControlsMenu
+	LevelSelect
 )
 
 type GameData struct {
-	Player *PlayerShip
-
-	Bullets   []*Bullet
-	Asteroids []*Asteroid
+	Game *sim.Game
+	// Seed is used for every new game when FixedSeed is set by the -seed flag
+	Seed      uint64
+	FixedSeed bool
 
 	Camera rl.Camera2D
+	// Viewport is where the play field sits in the window, before any shake
+	Viewport      rl.Rectangle
+	CameraEffects CameraEffects
+
+	// Accumulator holds frame time not yet consumed by fixed simulation steps
+	Accumulator float32
+	// PendingInput latches edge triggered keys until a step consumes them
+	PendingInput sim.Input
+
+	// Recording collects the inputs of the game being played, Playback feeds
+	// a recorded game back in the Replay state
+	Recording *sim.Replay
+	Playback  *Playback
+
+	// Levels are the levels on offer, LevelIndex the one played and
+	// HighScoresLevel the one whose scores are shown
+	Levels          []*sim.Level
+	LevelIndex      int32
+	HighScoresLevel int32
+
+	Particles *Particles
+	Music     *MusicPlayer
 
 	GameRunning bool
-	GameOver    bool
-	Paused      bool
-	Win         bool
 
 	GameState State
 
-	MenuIndex int32
+	MenuIndex   int32
+	MenuMessage string
 
-	FxShoot           rl.Sound
-	FxAsteroidDestroy rl.Sound
-	FxSpaceShipDead   rl.Sound
-	FxWin             rl.Sound
-}
+	Settings    Settings
+	OptionIndex int
 
-const shouldDrawBoundingBox = false
-const shouldDrawAsteroidInfo = false
-const shouldDrawStats = false
+	Input         InputState
+	Capture       Capture
+	ControlsIndex int
 
-const screenWidth float32 = 800
-const screenHeight float32 = 450
+	HighScores *scores.Table
+	NameEntry  NameEntry
+	// HighlightRank marks the score just entered on the high score screen
+	HighlightRank int
 
-const screenCenterX = screenWidth / 2
-const screenCenterY = screenHeight / 2
+	Assets    *Assets
+	Audio     *AudioManager
+	Heartbeat synth.Heartbeat
+}
+
+const screenWidth = sim.WorldWidth
+const screenHeight = sim.WorldHeight
+
+// maxFrameTime stops a long hitch from queueing up a burst of simulation steps
+const maxFrameTime float32 = 0.25
 
 func main() {
-	rl.InitWindow(int32(screenWidth), int32(screenHeight), "RayLib In Go")
+	var seed = flag.Uint64("seed", 0, "seed for the game's random number generator, random when omitted")
+	var replayPath = flag.String("replay", "", "play back a recorded replay file")
+	var assetsDir = flag.String("assets", "", "directory of asset files to use instead of the built in ones, the assets folder in the config directory when omitted")
+	flag.Parse()
+
+	var settings = LoadSettings()
+	if settings.VSync {
+		rl.SetConfigFlags(rl.FlagVsyncHint)
+	}
+	rl.InitWindow(settings.WindowSize.Width, settings.WindowSize.Height, "RayLib In Go")
 	defer rl.CloseWindow()
 
-	rl.SetTargetFPS(60)
 	rl.SetExitKey(rl.KeyNull)
 
 	rl.InitAudioDevice()
 	defer rl.CloseAudioDevice()
 
+	var assets = NewAssets(*assetsDir)
 	var data = &GameData{
-		Player:            nil,
-		Bullets:           []*Bullet{},
-		Asteroids:         []*Asteroid{},
-		Camera:            rl.NewCamera2D(rl.Vector2Zero(), rl.Vector2Zero(), 0, 1),
-		GameRunning:       true,
-		GameOver:          false,
-		Paused:            false,
-		Win:               false,
-		GameState:         Menu,
-		MenuIndex:         0,
-		FxShoot:           rl.LoadSound("assets/audio/shoot.wav"),
-		FxAsteroidDestroy: rl.LoadSound("assets/audio/asteroid_destroy.wav"),
-		FxSpaceShipDead:   rl.LoadSound("assets/audio/space_ship_dead.wav"),
-		FxWin:             rl.LoadSound("assets/audio/win.wav"),
-	}
-	defer rl.UnloadSound(data.FxShoot)
-	defer rl.UnloadSound(data.FxAsteroidDestroy)
-	defer rl.UnloadSound(data.FxSpaceShipDead)
-	defer rl.UnloadSound(data.FxWin)
-
-	RestartGame(data)
+		Seed:          *seed,
+		Settings:      settings,
+		Camera:        rl.NewCamera2D(rl.Vector2Zero(), rl.Vector2Zero(), 0, 1),
+		GameRunning:   true,
+		GameState:     Menu,
+		MenuIndex:     0,
+		HighScores:    LoadHighScores(),
+		HighlightRank: -1,
+		Particles:     NewParticles(),
+		Assets:        assets,
+		Levels:        LoadLevels(assets),
+		Music:         LoadMusicPlayer(assets),
+		Audio:         NewAudioManager(assets),
+	}
+	data.Audio.Load(SoundSpaceShipDead, "audio/space_ship_dead.wav", 1, 0)
+	data.Audio.Load(SoundWin, "audio/win.wav", 1, 0)
+	data.Audio.LoadSynth()
+	defer data.Audio.Unload()
+	defer data.Music.Unload()
+
+	ApplySettings(data, nil)
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			data.FixedSeed = true
+		}
+	})
+	NewGame(data)
+
+	if *replayPath != "" {
+		var replay, err = LoadReplay(*replayPath)
+		if err != nil {
+			rl.TraceLog(rl.LogWarning, "REPLAY: Failed to load %s: %s", *replayPath, err.Error())
+		} else {
+			StartPlayback(data, replay)
+		}
+	}
 
 	for data.GameRunning {
+		data.Input.Update(&data.Settings.Controls)
+		UpdateCamera(data)
 		rl.BeginDrawing()
 		rl.BeginMode2D(data.Camera)
 		rl.ClearBackground(rl.Black)
+		// the copies of things drawn across the screen edges would otherwise
+		// show in the black bars around the play field
+		rl.BeginScissorMode(int32(data.Viewport.X), int32(data.Viewport.Y), int32(data.Viewport.Width), int32(data.Viewport.Height))
 
 		switch data.GameState {
 		case Menu:
@@ -92,73 +159,127 @@ func main() {
 			ProcessInstructionsState(data)
 		case Game:
 			ProcessGameState(data)
+		case Replay:
+			ProcessReplayState(data)
+		case EnterName:
+			ProcessNameEntryState(data)
+		case HighScores:
+			ProcessHighScoresState(data)
+		case Options:
+			ProcessOptionsState(data)
+		case LevelSelect:
+			ProcessLevelSelectState(data)
+		case ControlsMenu:
+			ProcessControlsState(data)
 		}
 
+		data.Music.Update(data)
+
 		if rl.WindowShouldClose() {
 			data.GameRunning = false
 		}
 
+		rl.EndScissorMode()
 		rl.EndMode2D()
 		rl.EndDrawing()
 	}
+
+	if data.GameState == Game {
+		SaveRecording(data)
+	}
 }
 
 func ProcessInstructionsState(data *GameData) {
 	DrawTextCenter("Instructions", 70, 42, rl.Green)
 
-	var y float32 = 200
-	DrawTextCenter("Use W,A,S,D or ARROW KEYS to move and 'SPACE' to shoot", y, 18, rl.White)
+	var controls = &data.Settings.Controls
+	var y float32 = 140
+	for _, action := range []Action{ActionThrust, ActionRotateLeft, ActionRotateRight, ActionFire, ActionHyperspace, ActionPause, ActionRestart} {
+		DrawTextCenter(action.Name()+": "+controls.Describe(action), y, 16, rl.White)
+		y += 22
+	}
+	y += 20
+	DrawTextCenter("Clear each wave of asteroids and don't get hit by one", y, 18, rl.White)
 	y += 20
-	DrawTextCenter("Destroy all the asteroids to win and don't get hit by one", y, 18, rl.White)
+	DrawTextCenter(fmt.Sprintf("Shoot down saucers for %d or %d bonus points", sim.LargeSaucer.Points(), sim.SmallSaucer.Points()), y, 18, rl.White)
+	y += 20
+	DrawTextCenter(fmt.Sprintf("You start with %d lives and earn another every %d points", sim.StartingLives, sim.ExtraLifeScore), y, 18, rl.White)
+	y += 20
+	DrawTextCenter("Hyperspace jumps somewhere at random, but the ship may not survive it", y, 18, rl.White)
 
 	y = 400
 	DrawMenuItem("Back", y, true)
 
-	if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeySpace) {
+	if data.Input.Pressed(ActionMenuConfirm) || data.Input.Pressed(ActionMenuBack) {
 		data.GameState = Menu
 	}
 }
 
+type MenuItem struct {
+	Text   string
+	Select func(data *GameData)
+}
+
+var mainMenu = []MenuItem{
+	{"Play", func(data *GameData) {
+		NewGame(data)
+		data.GameState = Game
+	}},
+	{"Levels", func(data *GameData) {
+		data.GameState = LevelSelect
+	}},
+	{"Instructions", func(data *GameData) {
+		data.GameState = Instructions
+	}},
+	{"High Scores", func(data *GameData) {
+		data.HighScoresLevel = data.LevelIndex
+		data.GameState = HighScores
+	}},
+	{"Options", func(data *GameData) {
+		data.OptionIndex = 0
+		data.GameState = Options
+	}},
+	{"Watch Replay", WatchLastReplay},
+	{"Quit", func(data *GameData) {
+		data.GameRunning = false
+	}},
+}
+
 func ProcessMenuState(data *GameData) {
 	DrawTextCenter("SPACE DROID", 70, 42, rl.Green)
 
 	var y float32 = 150
+	for i, item := range mainMenu {
+		DrawMenuItem(item.Text, y, data.MenuIndex == int32(i))
+		y += 32
+	}
 
-	DrawMenuItem("Play", y, data.MenuIndex == 0)
-	y += 50
-	DrawMenuItem("Instructions", y, data.MenuIndex == 1)
-	y += 50
-	DrawMenuItem("Quit", y, data.MenuIndex == 2)
+	if data.MenuMessage != "" {
+		DrawTextCenter(data.MenuMessage, y, 16, rl.Red)
+	}
 
 	y = 400
 	DrawTextCenter("Made by Ruben Bezuidenhout", y, 18, rl.Blue)
 
-	if rl.IsKeyPressed(rl.KeyDown) || rl.IsKeyPressed(rl.KeyS) {
+	var count = int32(len(mainMenu))
+	if data.Input.Pressed(ActionMenuDown) {
 		data.MenuIndex++
-		data.MenuIndex %= 3
+		data.MenuIndex %= count
+		data.MenuMessage = ""
 	}
 
-	if rl.IsKeyPressed(rl.KeyUp) || rl.IsKeyPressed(rl.KeyW) {
+	if data.Input.Pressed(ActionMenuUp) {
 		data.MenuIndex--
 		if data.MenuIndex < 0 {
-			data.MenuIndex = 2
+			data.MenuIndex = count - 1
 		}
-		data.MenuIndex %= 3
+		data.MenuIndex %= count
+		data.MenuMessage = ""
 	}
 
-	if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeySpace) {
-		if data.MenuIndex == 0 {
-			RestartGame(data)
-			data.GameState = Game
-		}
-
-		if data.MenuIndex == 1 {
-			data.GameState = Instructions
-		}
-
-		if data.MenuIndex == 2 {
-			data.GameRunning = false
-		}
+	if data.Input.Pressed(ActionMenuConfirm) {
+		data.MenuMessage = ""
+		mainMenu[data.MenuIndex].Select(data)
 	}
 }
 
@@ -181,246 +302,306 @@ func DrawTextCenter(text string, y float32, fontSize int32, color rl.Color) {
 }
 
 func ProcessGameState(data *GameData) {
-	if data.Paused {
-		DrawTextCenter("PAUSE", screenHeight/2, 20, rl.Red)
-	} else {
-		if !data.Win {
-			if !data.GameOver {
-				ProcessPlayer(data)
-				ProcessBullets(data)
-				ProcessAsteroids(data)
-				ProcessCollision(data)
-			} else {
-				DrawTextCenter("GAME OVER", screenHeight/2, 20, rl.Red)
-				DrawTextCenter("PRESS 'R' TO TRY AGAIN", (screenHeight+40)/2, 20, rl.Red)
-			}
-		} else {
-			DrawTextCenter("YOU WON!!", screenHeight/2, 20, rl.Gold)
-			DrawTextCenter("PRESS 'R' TO TRY AGAIN", (screenHeight+40)/2, 20, rl.Gold)
+	var game = data.Game
+	var input = &data.Input
+	data.PendingInput.Thrust = input.Down(ActionThrust)
+	data.PendingInput.RotateLeft = input.DigitalDown(ActionRotateLeft)
+	data.PendingInput.RotateRight = input.DigitalDown(ActionRotateRight)
+	data.PendingInput.Turn = input.Turn()
+	data.PendingInput.Fire = data.PendingInput.Fire || input.Pressed(ActionFire)
+	data.PendingInput.Hyperspace = data.PendingInput.Hyperspace || input.Pressed(ActionHyperspace)
+	data.PendingInput.Pause = data.PendingInput.Pause || input.Pressed(ActionPause)
+	data.PendingInput.Restart = data.PendingInput.Restart || input.Pressed(ActionRestart)
+
+	// a finished game's recording is already saved, so playing again starts a
+	// new game with a recording of its own
+	if game.GameOver && data.PendingInput.Restart {
+		NewGame(data)
+		game = data.Game
+	}
+
+	data.Accumulator += GameFrameTime(data)
+	for data.Accumulator >= sim.TickDuration {
+		if data.Recording != nil {
+			data.Recording.Record(data.PendingInput)
 		}
-	}
+		StepGame(data, data.PendingInput)
+		data.Accumulator -= sim.TickDuration
 
-	if rl.IsKeyPressed(rl.KeyP) {
-		data.Paused = !data.Paused
-	}
+		// stop recording as the game ends, rather than idling on the game
+		// over screen
+		if game.GameOver {
+			SaveRecording(data)
+		}
 
-	if rl.IsKeyPressed(rl.KeyEscape) {
-		data.GameState = Menu
+		data.PendingInput.Fire = false
+		data.PendingInput.Hyperspace = false
+		data.PendingInput.Pause = false
+		data.PendingInput.Restart = false
 	}
+	var alpha = data.Accumulator / sim.TickDuration
 
-	if rl.IsKeyPressed(rl.KeyR) {
-		RestartGame(data)
+	if input.Pressed(ActionMenuBack) {
+		SaveRecording(data)
+		data.CameraEffects.Clear()
+		data.GameState = Menu
 	}
 
-	DrawPlayer(data.Player)
-	for i := range data.Bullets {
-		DrawBullet(data.Bullets[i])
-	}
-	for i := range data.Asteroids {
-		DrawAsteroid(data.Asteroids[i])
-		if shouldDrawAsteroidInfo {
-			data.Asteroids[i].DrawInfo()
-		}
-	}
+	DrawGame(data, alpha)
 
-	if shouldDrawStats {
-		DrawStats(data)
+	if game.GameOver && !data.Particles.BreakingUp() && data.HighScores.Qualifies(game.Level.Name, game.Score) {
+		StartNameEntry(data)
 	}
 }
 
-func RestartGame(data *GameData) {
-	data.GameOver = false
-	data.Paused = false
-	data.Win = false
-	for i := range data.Bullets {
-		data.Bullets[i] = nil
+// GameFrameTime is how long the game should run for this frame, less any
+// hit-stop. The camera effects are held while the game is paused and carry on
+// where they left off, though the game still runs so it can be unpaused.
+func GameFrameTime(data *GameData) float32 {
+	var frameTime = min(rl.GetFrameTime(), maxFrameTime)
+	if data.Game.Paused {
+		return frameTime
 	}
-	data.Bullets = []*Bullet{}
+	return data.CameraEffects.Update(frameTime)
+}
 
-	for i := range data.Asteroids {
-		data.Asteroids[i] = nil
+func DrawGame(data *GameData, alpha float32) {
+	var game = data.Game
+	if game.Paused {
+		DrawTextCenter("PAUSE", screenHeight/2, 20, rl.Red)
+	} else if game.Won {
+		DrawTextCenter("LEVEL COMPLETE", screenHeight/2, 20, rl.Gold)
+		DrawTextCenter(fmt.Sprintf("PRESS '%s' TO PLAY AGAIN", data.Settings.Controls.Primary(ActionRestart)), (screenHeight+40)/2, 20, rl.Gold)
+	} else if game.GameOver {
+		// let the ship finish breaking up first
+		if !data.Particles.BreakingUp() {
+			DrawTextCenter("GAME OVER", screenHeight/2, 20, rl.Red)
+			DrawTextCenter(fmt.Sprintf("PRESS '%s' TO TRY AGAIN", data.Settings.Controls.Primary(ActionRestart)), (screenHeight+40)/2, 20, rl.Red)
+		}
+	} else if game.WaveTimer > 0 {
+		DrawTextCenter(fmt.Sprintf("WAVE %d", game.Wave+1), screenHeight/2, 20, rl.Gold)
 	}
-	data.Asteroids = []*Asteroid{}
 
-	data.Player = nil
-	data.Player = NewPlayerShip(rl.NewVector2(screenCenterX, screenCenterY), 0, 20.0, 2)
+	data.Particles.Draw()
 
-	//SpawnAsteroid(data, rl.NewVector2(150, 150), float32(0), 0, Small)
-	//SpawnAsteroid(data, rl.NewVector2(150, 250), float32(0), 0, Medium)
-	//SpawnAsteroid(data, rl.NewVector2(150, 350), float32(0), 0, Large)
-	for range 10 {
-		var size = AsteroidSize(rl.GetRandomValue(int32(Small), int32(Large)))
-		var x = rl.GetRandomValue(0, int32(screenWidth)/2)
-		var y = rl.GetRandomValue(0, int32(screenHeight)/2)
-		var rotation = rl.GetRandomValue(0, 360)
-		SpawnAsteroid(data, rl.NewVector2(float32(x), float32(y)), float32(rotation), 1, size)
+	// blink while the ship is invulnerable after spawning
+	if game.Player != nil && int32(game.Player.Invulnerable*8)%2 == 0 {
+		DrawPlayer(game.Player, alpha)
 	}
-}
-
-func ProcessCollision(data *GameData) {
-	for _, b := range data.Bullets {
-		for _, a := range data.Asteroids {
-			if CheckCollisionPoly(a.GetScaledRenderPoints(), GetPointsFromRectSlice(b.GetBoundingBox())) {
-				if a.Size == Large {
-					SpawnAsteroid(data, a.Position, a.Rotation+GetRandomAngle(), a.Speed+GetRandomValueF(0, 5)/5, a.Size-1)
-					SpawnAsteroid(data, a.Position, a.Rotation+GetRandomAngle(), a.Speed+GetRandomValueF(0, 5)/5, a.Size-1)
-					SpawnAsteroid(data, a.Position, a.Rotation+GetRandomAngle(), a.Speed+GetRandomValueF(0, 5)/5, a.Size-1)
-					SpawnAsteroid(data, a.Position, a.Rotation+GetRandomAngle(), a.Speed+GetRandomValueF(0, 5)/5, a.Size-1)
-				}
-				if a.Size == Medium {
-					SpawnAsteroid(data, a.Position, a.Rotation+GetRandomAngle(), a.Speed+GetRandomValueF(0, 3)/3, a.Size-1)
-					SpawnAsteroid(data, a.Position, a.Rotation+GetRandomAngle(), a.Speed+GetRandomValueF(0, 3)/3, a.Size-1)
-				}
-				rl.PlaySound(data.FxAsteroidDestroy)
-				a.ShouldDelete = true
-				b.ShouldDelete = true
-			}
-		}
+	if game.Saucer != nil {
+		DrawSaucer(game.Saucer, alpha)
 	}
-
-	for _, a := range data.Asteroids {
-		if CheckCollisionPoly(data.Player.GetScaledRenderPoints(), a.GetScaledRenderPoints()) {
-			rl.PlaySound(data.FxSpaceShipDead)
-			data.GameOver = true
-		}
+	for _, bullet := range game.Bullets.Items() {
+		DrawBullet(bullet, alpha)
 	}
-}
-
-func DrawAsteroid(asteroid *Asteroid) {
-	DrawLines(asteroid.Position, asteroid.Rotation, asteroid.Scale, asteroid.RenderPoints)
-
-	DrawBoundingBox(asteroid.GetBoundingBox(), asteroid.Rotation)
-}
-
-func ProcessAsteroids(data *GameData) {
-	for i := len(data.Asteroids) - 1; i >= 0; i-- {
-		if data.Asteroids[i].ShouldDelete {
-			data.Asteroids[i] = nil
-			data.Asteroids = append(data.Asteroids[:i], data.Asteroids[i+1:]...)
+	for _, asteroid := range game.Asteroids.Items() {
+		DrawAsteroid(asteroid, alpha)
+		if data.Settings.DrawAsteroidInfo {
+			DrawAsteroidInfo(asteroid)
 		}
 	}
 
-	for i := range data.Asteroids {
-		var theta = float64(DegToRad(data.Asteroids[i].Rotation))
-		var direction = rl.NewVector2(float32(math.Cos(theta)), float32(math.Sin(theta)))
-		data.Asteroids[i].Position = rl.Vector2Add(data.Asteroids[i].Position, rl.Vector2Multiply(direction, rl.NewVector2(data.Asteroids[i].Speed, data.Asteroids[i].Speed)))
-
-		data.Asteroids[i].Position = WrapCoordinates(data.Asteroids[i].Position)
-	}
-}
-
-func ProcessBullets(data *GameData) {
-	// Cleanup before processing again
-	for i := len(data.Bullets) - 1; i >= 0; i-- {
-		if data.Bullets[i].ShouldDelete {
-			data.Bullets[i] = nil
-			data.Bullets = append(data.Bullets[:i], data.Bullets[i+1:]...)
-		}
+	if data.Settings.DrawBoundingBoxes {
+		DrawBoundingBoxes(game)
 	}
 
-	for i := range data.Bullets {
-		data.Bullets[i].Lifetime -= rl.GetFrameTime()
+	DrawHud(game)
 
-		if data.Bullets[i].Lifetime <= 0 {
-			data.Bullets[i].ShouldDelete = true
-		}
-		var theta = float64(DegToRad(data.Bullets[i].Rotation))
-		var direction = rl.NewVector2(float32(math.Cos(theta)), float32(math.Sin(theta)))
-		data.Bullets[i].Position = rl.Vector2Add(data.Bullets[i].Position, rl.Vector2Multiply(direction, rl.NewVector2(data.Bullets[i].Speed, data.Bullets[i].Speed)))
+	if data.Settings.DrawStats {
+		DrawStats(data)
 	}
 }
 
-func ProcessPlayer(data *GameData) {
-	var player = data.Player
-	const drag = 0.015
+func DrawHud(game *sim.Game) {
+	rl.DrawText(fmt.Sprintf("%d", game.Score), 10, 10, 20, rl.RayWhite)
 
-	var theta = float64(DegToRad(player.Rotation))
-	var lookDirection = rl.NewVector2(float32(math.Cos(theta)), float32(math.Sin(theta)))
-	if rl.IsKeyDown(rl.KeyW) || rl.IsKeyDown(rl.KeyUp) {
-		player.Velocity = rl.Vector2Add(player.Velocity, rl.Vector2Scale(lookDirection, player.Speed*rl.GetFrameTime()))
-	}
+	var wave = fmt.Sprintf("WAVE %d", game.Wave)
+	var size = rl.MeasureTextEx(rl.GetFontDefault(), wave, 20, 0)
+	rl.DrawText(wave, int32(screenWidth-size.X-10), 10, 20, rl.RayWhite)
 
-	if rl.IsKeyDown(rl.KeyA) || rl.IsKeyDown(rl.KeyLeft) {
-		player.Rotation -= 3
+	for i := range game.Lives {
+		DrawLines(sim.NewVector2(float32(20+i*16), 45), 180, 12, sim.ShipOutline)
 	}
 
-	if rl.IsKeyDown(rl.KeyD) || rl.IsKeyDown(rl.KeyRight) {
-		player.Rotation += 3
+	// hyperspace charge, lit up once the ship can jump again
+	var charge = game.HyperspaceCharge()
+	var color = rl.Gray
+	if charge >= 1 {
+		color = rl.SkyBlue
 	}
+	rl.DrawRectangle(12, 56, int32(60*charge), 4, color)
+	rl.DrawRectangleLines(12, 56, 60, 4, color)
+}
 
-	if rl.IsKeyPressed(rl.KeySpace) {
-		rl.PlaySound(data.FxShoot)
-		SpawnBullet(data, player.Position, player.Rotation, 8)
+func NewGame(data *GameData) {
+	var seed = data.Seed
+	if !data.FixedSeed {
+		seed = rand.Uint64()
 	}
+	rl.TraceLog(rl.LogInfo, "GAME: Starting game with seed %d", seed)
+
+	var level = data.Levels[data.LevelIndex]
+	rl.TraceLog(rl.LogInfo, "GAME: Playing level %s", level.Name)
 
-	player.Velocity = rl.Vector2Scale(player.Velocity, 1-drag)
-	player.Position = rl.Vector2Add(player.Position, player.Velocity)
+	var options = sim.Options{AsteroidPhysics: data.Settings.AsteroidPhysics, HyperspaceRisk: data.Settings.HyperspaceRisk, Level: level}
+	data.Game = sim.NewGame(seed, options)
+	data.Recording = &sim.Replay{Seed: seed, Options: options}
+	data.Accumulator = 0
+	data.PendingInput = sim.Input{}
+	data.Particles.Clear()
+	data.CameraEffects.Clear()
+	data.Heartbeat.Reset()
+}
 
-	data.Player.Position = WrapCoordinates(data.Player.Position)
+// StepGame advances the game by one step, along with the sounds, particles
+// and camera effects that go with it.
+func StepGame(data *GameData, input sim.Input) {
+	var events = data.Game.Step(input)
+	PlayEvents(data, events)
+	PlayGameSounds(data)
+	data.Particles.Step(data.Game, events)
+	data.CameraEffects.Trigger(events, data.Settings.Motion)
+}
 
-	if len(data.Asteroids) == 0 {
-		rl.PlaySound(data.FxWin)
-		data.Win = true
+// PlayEvents turns what happened during a simulation step into sounds.
+func PlayEvents(data *GameData, events []sim.Event) {
+	for _, event := range events {
+		switch event.Type {
+		case sim.ShotFired:
+			data.Audio.Play(SoundShoot, event.Position.X)
+		case sim.AsteroidDestroyed:
+			data.Audio.Play(ExplosionSound(event.Size), event.Position.X)
+		case sim.SaucerDestroyed:
+			data.Audio.Play(SoundExplosionLarge, event.Position.X)
+		case sim.ShipDied:
+			data.Audio.Play(SoundSpaceShipDead, event.Position.X)
+			data.Music.Duck(data.Audio.Length(SoundSpaceShipDead))
+		case sim.WaveCleared, sim.ExtraLife, sim.LevelCompleted:
+			// not tied to anywhere on screen, so played in the middle
+			data.Audio.Play(SoundWin, screenWidth/2)
+			data.Music.Duck(data.Audio.Length(SoundWin))
+		case sim.HyperspaceEntered:
+			data.Audio.Play(SoundHyperspace, event.Position.X)
+		}
 	}
 }
 
+func DrawAsteroid(asteroid *sim.Asteroid, alpha float32) {
+	var position = sim.Interpolate(asteroid.PrevPosition, asteroid.Position, alpha)
+	var rotation = sim.Lerp(asteroid.PrevRotation, asteroid.Rotation, alpha)
+	DrawLinesWrapped(position, asteroid.Radius, rotation, asteroid.Scale, asteroid.RenderPoints)
+}
+
+func DrawAsteroidInfo(asteroid *sim.Asteroid) {
+	var text = "Size:" + asteroid.Size.Name()
+	var textSize = rl.MeasureTextEx(rl.GetFontDefault(), text, 10, 0)
+	var boundingBox = asteroid.GetBoundingBox()
+	var position = rl.NewVector2(boundingBox.X+boundingBox.Width/2, (boundingBox.Y-boundingBox.Height/2)-textSize.Y)
+	rl.DrawText(text, int32(position.X), int32(position.Y), 10, rl.RayWhite)
+}
+
 func DrawStats(data *GameData) {
-	var y int32 = 10
-	rl.DrawText(fmt.Sprintf("Number of Bullets: %d", len(data.Bullets)), 2, y, 10, rl.RayWhite)
+	var y int32 = 70
+	rl.DrawText(fmt.Sprintf("Number of Bullets: %d", data.Game.Bullets.Len()), 2, y, 10, rl.RayWhite)
+	y += 10
+	rl.DrawText(fmt.Sprintf("Number of astroids: %d", data.Game.Asteroids.Len()), 2, y, 10, rl.RayWhite)
 	y += 10
-	rl.DrawText(fmt.Sprintf("Number of astroids: %d", len(data.Asteroids)), 2, y, 10, rl.RayWhite)
+	if data.Game.Player != nil {
+		rl.DrawText(fmt.Sprintf("Player pos: %f.0, %f.0", data.Game.Player.Position.X, data.Game.Player.Position.Y), 2, y, 10, rl.RayWhite)
+	}
 	y += 10
-	rl.DrawText(fmt.Sprintf("Player pos: %f.0, %f.0", data.Player.Position.X, data.Player.Position.Y), 2, y, 10, rl.RayWhite)
+	rl.DrawText(fmt.Sprintf("Seed: %d", data.Game.Rng.Seed), 2, y, 10, rl.RayWhite)
 }
 
-func SpawnBullet(data *GameData, spawnPosition rl.Vector2, rotation float32, speed float32) {
-	var bullet = NewBullet(spawnPosition, 10, rotation, speed, 1)
-	data.Bullets = append(data.Bullets, bullet)
+func DrawBullet(bullet *sim.Bullet, alpha float32) {
+	var position = sim.Interpolate(bullet.PrevPosition, bullet.Position, alpha)
+	DrawLinesWrapped(position, bullet.Radius, bullet.Rotation+45, bullet.Scale, []sim.Vector2{
+		sim.NewVector2(0.5, 0.5),
+		sim.NewVector2(0.5, -0.5),
+		sim.NewVector2(-0.5, -0.5),
+	})
 }
 
-func SpawnAsteroid(data *GameData, spawnPosition rl.Vector2, rotation float32, speed float32, size AsteroidSize) {
-	var asteroid = NewAsteroid(spawnPosition, rotation, size, speed)
-	data.Asteroids = append(data.Asteroids, asteroid)
+func DrawPlayer(player *sim.PlayerShip, alpha float32) {
+	var position = sim.Interpolate(player.PrevPosition, player.Position, alpha)
+	var rotation = sim.Lerp(player.PrevRotation, player.Rotation, alpha)
+	DrawLinesWrapped(position, player.Radius, rotation-90, player.Scale, player.RenderPoints)
 }
 
-func DrawBullet(bullet *Bullet) {
-	DrawLines(bullet.Position, bullet.Rotation+45, bullet.Scale, []rl.Vector2{
-		rl.NewVector2(0.5, 0.5),
-		rl.NewVector2(0.5, -0.5),
-		rl.NewVector2(-0.5, -0.5),
-	})
-
-	DrawBoundingBox(bullet.GetBoundingBox(), bullet.Rotation)
+// saucerDetail is the rim and the base of the dome drawn across the saucer.
+var saucerDetail = [][]sim.Vector2{
+	{sim.NewVector2(-1, 0), sim.NewVector2(1, 0)},
+	{sim.NewVector2(-0.45, -0.3), sim.NewVector2(0.45, -0.3)},
 }
 
-func DrawPlayer(player *PlayerShip) {
-	DrawLines(player.Position, player.Rotation-90, player.Scale, player.RenderPoints)
+func DrawSaucer(saucer *sim.Saucer, alpha float32) {
+	var position = sim.Interpolate(saucer.PrevPosition, saucer.Position, alpha)
+	DrawLinesWrapped(position, saucer.Radius, 0, saucer.Scale, sim.SaucerOutline)
+	for _, line := range saucerDetail {
+		DrawLinesWrapped(position, saucer.Radius, 0, saucer.Scale, line)
+	}
+}
 
-	DrawBoundingBox(player.GetBoundingBox(), player.Rotation)
+// DrawLinesWrapped draws the shape again on the opposite side of any screen
+// edge it overlaps, so things slide across the edges instead of popping.
+func DrawLinesWrapped(position sim.Vector2, radius float32, rotation float32, scale float32, points []sim.Vector2) {
+	var offsets, count = sim.WrapOffsets(position, radius)
+	for _, offset := range offsets[:count] {
+		DrawLines(sim.Vector2Add(position, offset), rotation, scale, points)
+	}
 }
 
-func DrawLines(position rl.Vector2, rotation float32, scale float32, points []rl.Vector2) {
-	var transform = func(point rl.Vector2) rl.Vector2 {
-		return rl.Vector2Add(rl.Vector2Scale(rl.Vector2Rotate(point, DegToRad(rotation)), scale), position)
+func DrawLines(position sim.Vector2, rotation float32, scale float32, points []sim.Vector2) {
+	var transform = func(point sim.Vector2) rl.Vector2 {
+		return rl.Vector2(sim.Vector2Add(sim.Vector2Scale(sim.Vector2Rotate(point, sim.DegToRad(rotation)), scale), position))
 	}
 	for i := range points {
 		rl.DrawLineEx(transform(points[i]), transform(points[(i+1)%len(points)]), 2, rl.White)
 	}
 }
 
-func DrawBoundingBox(boundingBox rl.Rectangle, rotation float32) {
-	var a1, a2, a3, a4 = GetPointsFromRect(boundingBox)
+func DrawBoundingBox(boundingBox sim.Rectangle, rotation float32) {
+	var a1, a2, a3, a4 = sim.GetPointsFromRect(boundingBox)
 
-	var pos = rl.NewVector2(boundingBox.X, boundingBox.Y)
+	var pos = sim.NewVector2(boundingBox.X, boundingBox.Y)
 
-	var transform = func(point rl.Vector2) rl.Vector2 {
-		return rl.Vector2Add(rl.Vector2Scale(rl.Vector2Rotate(rl.Vector2Subtract(point, pos), DegToRad(rotation)), 1), pos)
+	var transform = func(point sim.Vector2) rl.Vector2 {
+		return rl.Vector2(sim.Vector2Add(sim.Vector2Scale(sim.Vector2Rotate(sim.Vector2Subtract(point, pos), sim.DegToRad(rotation)), 1), pos))
 	}
 
-	if shouldDrawBoundingBox {
-		rl.DrawLineEx(transform(a1), transform(a2), 2, rl.Pink)
-		rl.DrawLineEx(transform(a2), transform(a3), 2, rl.Pink)
-		rl.DrawLineEx(transform(a3), transform(a4), 2, rl.Pink)
-		rl.DrawLineEx(transform(a4), transform(a1), 2, rl.Pink)
+	rl.DrawLineEx(transform(a1), transform(a2), 2, rl.Pink)
+	rl.DrawLineEx(transform(a2), transform(a3), 2, rl.Pink)
+	rl.DrawLineEx(transform(a3), transform(a4), 2, rl.Pink)
+	rl.DrawLineEx(transform(a4), transform(a1), 2, rl.Pink)
+}
+
+func DrawCollisionPolygon(points []sim.Vector2) {
+	for i := range points {
+		rl.DrawLineEx(rl.Vector2(points[i]), rl.Vector2(points[(i+1)%len(points)]), 2, rl.Pink)
+	}
+}
+
+// DrawBoundingBoxes is a debug overlay of the shapes used for collision, with
+// each convex piece outlined separately.
+func DrawBoundingBoxes(game *sim.Game) {
+	if game.Player != nil {
+		DrawBoundingBox(game.Player.GetBoundingBox(), game.Player.Rotation)
+		for _, piece := range sim.TransformPieces(nil, game.Player.Pieces, game.Player.Position, game.Player.Rotation-90, game.Player.Scale) {
+			DrawCollisionPolygon(piece)
+		}
+	}
+	if game.Saucer != nil {
+		for _, piece := range sim.TransformPieces(nil, game.Saucer.Pieces, game.Saucer.Position, 0, game.Saucer.Scale) {
+			DrawCollisionPolygon(piece)
+		}
+	}
+	for _, bullet := range game.Bullets.Items() {
+		DrawBoundingBox(bullet.GetBoundingBox(), bullet.Rotation)
+	}
+	for _, asteroid := range game.Asteroids.Items() {
+		DrawBoundingBox(asteroid.GetBoundingBox(), asteroid.Rotation)
+		for _, piece := range sim.TransformPieces(nil, asteroid.Pieces, asteroid.Position, asteroid.Rotation, asteroid.Scale) {
+			DrawCollisionPolygon(piece)
+		}
 	}
 }
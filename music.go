@@ -0,0 +1,203 @@
+package main
+
+import (
+	"SpaceDroid/synth"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type MusicTrack int32
+
+const (
+	TrackMenu MusicTrack = iota
+	TrackGame
+	TrackGameOver
+	trackCount
+)
+
+// musicFile holds the songs every track is synthesized from.
+const musicFile = "audio/music.json"
+
+// musicSongs are the songs of each track. A WAV of the same name in the
+// audio folder of the override assets, such as audio/music_menu.wav, plays
+// in its place.
+var musicSongs = [trackCount]string{
+	TrackMenu:     "menu",
+	TrackGame:     "game",
+	TrackGameOver: "game_over",
+}
+
+// intenseLayerSong plays in time with the game track, faded in as a wave gets
+// cleared.
+const intenseLayerSong = "game_intense"
+
+// crossfadeTime is how many seconds one track takes to fade into the next.
+const crossfadeTime float32 = 1.5
+
+// duckVolume is how loud the music drops to while a big sound effect plays,
+// and duckFadeTime how many seconds it takes to get there and back.
+const duckVolume float32 = 0.35
+const duckFadeTime float32 = 0.15
+
+// maxTempoUp is how much faster the game track plays at full intensity.
+const maxTempoUp float32 = 0.08
+
+// MusicPlayer streams a track for each group of states, crossfading between
+// them. Tracks that failed to load, or all of them without an audio device,
+// are left silent.
+type MusicPlayer struct {
+	tracks  [trackCount]rl.Music
+	fades   [trackCount]float32
+	intense rl.Music
+	// intensity follows how much of the wave has been cleared
+	intensity float32
+	// duckTimer counts down while a sound effect wants the music quieter, and
+	// duck eases between 1 and duckVolume to follow it
+	duckTimer float32
+	duck      float32
+	// rendered keeps the synthesized tracks alive, since raylib streams them
+	// from memory as they play
+	rendered [][]byte
+}
+
+func LoadMusicPlayer(assets *Assets) *MusicPlayer {
+	var m = &MusicPlayer{duck: 1}
+	if !rl.IsAudioDeviceReady() {
+		return m
+	}
+
+	var songs, ok = assets.LoadSongs(musicFile)
+	if !ok {
+		rl.TraceLog(rl.LogWarning, "MUSIC: Failed to load %s, playing only overridden tracks", musicFile)
+		songs = &synth.Music{}
+	}
+	for track, song := range musicSongs {
+		m.tracks[track] = m.loadMusic(assets, songs, song)
+	}
+	m.intense = m.loadMusic(assets, songs, intenseLayerSong)
+	return m
+}
+
+// loadMusic streams the override WAV of a song if there is one, and otherwise
+// synthesizes it.
+func (m *MusicPlayer) loadMusic(assets *Assets, songs *synth.Music, name string) rl.Music {
+	var file = "audio/music_" + name + ".wav"
+	if assets.Overridden(file) {
+		if music, ok := assets.LoadMusic(file); ok {
+			return music
+		}
+	}
+
+	var song, ok = songs.Songs[name]
+	if !ok {
+		rl.TraceLog(rl.LogWarning, "MUSIC: No song %s, playing without it", name)
+		return rl.Music{}
+	}
+	var wav = synth.EncodeWAV(songs.Render(&song, synth.SampleRate), synth.SampleRate)
+	var music = rl.LoadMusicStreamFromMemory(".wav", wav, int32(len(wav)))
+	if !rl.IsMusicReady(music) {
+		rl.TraceLog(rl.LogWarning, "MUSIC: Failed to load song %s, playing without it", name)
+		return rl.Music{}
+	}
+	m.rendered = append(m.rendered, wav)
+	return music
+}
+
+func (m *MusicPlayer) Unload() {
+	for _, music := range m.tracks {
+		if rl.IsMusicReady(music) {
+			rl.UnloadMusicStream(music)
+		}
+	}
+	if rl.IsMusicReady(m.intense) {
+		rl.UnloadMusicStream(m.intense)
+	}
+}
+
+// Duck lowers the music for the next seconds so a sound can be heard over it.
+func (m *MusicPlayer) Duck(seconds float32) {
+	m.duckTimer = max(m.duckTimer, seconds)
+}
+
+// TrackFor is the track that goes with what is on screen.
+func TrackFor(data *GameData) MusicTrack {
+	switch data.GameState {
+	case Game, Replay:
+		if data.Game.GameOver && !data.Particles.BreakingUp() {
+			return TrackGameOver
+		}
+		return TrackGame
+	case EnterName:
+		return TrackGameOver
+	}
+	return TrackMenu
+}
+
+// Update fades the tracks towards the one for the current state and keeps
+// the streams fed. It must be called once every frame.
+func (m *MusicPlayer) Update(data *GameData) {
+	var dt = rl.GetFrameTime()
+	var current = TrackFor(data)
+
+	for track := range trackCount {
+		var music = m.tracks[track]
+		if !rl.IsMusicReady(music) {
+			continue
+		}
+
+		if track == current {
+			if !rl.IsMusicStreamPlaying(music) {
+				rl.PlayMusicStream(music)
+				if track == TrackGame && rl.IsMusicReady(m.intense) {
+					// start together so the layers stay in time
+					rl.PlayMusicStream(m.intense)
+				}
+			}
+			m.fades[track] = min(1, m.fades[track]+dt/crossfadeTime)
+		} else {
+			m.fades[track] = max(0, m.fades[track]-dt/crossfadeTime)
+			if m.fades[track] == 0 && rl.IsMusicStreamPlaying(music) {
+				rl.StopMusicStream(music)
+				if track == TrackGame && rl.IsMusicReady(m.intense) {
+					rl.StopMusicStream(m.intense)
+				}
+			}
+		}
+	}
+
+	var intensity float32 = 0
+	if current == TrackGame && !data.Game.Paused {
+		intensity = data.Game.WaveProgress()
+	}
+	// ease towards the target so a split asteroid doesn't jolt the music
+	m.intensity += (intensity - m.intensity) * min(1, dt*2)
+
+	m.duckTimer = max(0, m.duckTimer-dt)
+	var duck float32 = 1
+	if m.duckTimer > 0 {
+		duck = duckVolume
+	}
+	if m.duck < duck {
+		m.duck = min(duck, m.duck+dt/duckFadeTime)
+	} else {
+		m.duck = max(duck, m.duck-dt/duckFadeTime)
+	}
+
+	var volume = data.Settings.MusicVolume * m.duck
+	for track := range trackCount {
+		var music = m.tracks[track]
+		if rl.IsMusicReady(music) && rl.IsMusicStreamPlaying(music) {
+			rl.SetMusicVolume(music, volume*m.fades[track])
+			rl.UpdateMusicStream(music)
+		}
+	}
+
+	var pitch = 1 + maxTempoUp*m.intensity
+	if rl.IsMusicReady(m.tracks[TrackGame]) {
+		rl.SetMusicPitch(m.tracks[TrackGame], pitch)
+	}
+	if rl.IsMusicReady(m.intense) && rl.IsMusicStreamPlaying(m.intense) {
+		rl.SetMusicPitch(m.intense, pitch)
+		rl.SetMusicVolume(m.intense, volume*m.fades[TrackGame]*m.intensity)
+		rl.UpdateMusicStream(m.intense)
+	}
+}
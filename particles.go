@@ -0,0 +1,197 @@
+package main
+
+import (
+	"SpaceDroid/sim"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"math/rand/v2"
+)
+
+// maxParticles caps how many particles are alive at once. Past that new ones
+// are dropped, which nobody notices in the middle of that much going on.
+const maxParticles = 1024
+
+// shipBreakUpTime is how long the pieces of a destroyed ship drift apart
+// before fading out, which the GAME OVER text waits for.
+const shipBreakUpTime float32 = 2
+
+type ParticleKind int32
+
+const (
+	// Spark is a short streak along the direction it is moving
+	Spark ParticleKind = iota
+	// Segment is a spinning piece of an outline
+	Segment
+)
+
+type Particle struct {
+	Kind     ParticleKind
+	Position sim.Vector2
+	Velocity sim.Vector2
+	// Start and End are the ends of a Segment around Position before turning
+	// it by Rotation degrees
+	Start           sim.Vector2
+	End             sim.Vector2
+	Rotation        float32
+	AngularVelocity float32
+	// Life counts down from Lifetime, fading the particle out as it goes
+	Life     float32
+	Lifetime float32
+	Color    rl.Color
+}
+
+// Particles are purely for show, so they use their own randomness and leave
+// the simulation and its replays alone.
+type Particles struct {
+	pool sim.Pool[Particle]
+	// breakUp counts down while the pieces of a destroyed ship are flying
+	breakUp float32
+}
+
+func NewParticles() *Particles {
+	var p = &Particles{}
+	p.pool.Reserve(maxParticles, nil)
+	return p
+}
+
+func (p *Particles) Clear() {
+	p.pool.Clear()
+	p.breakUp = 0
+}
+
+// BreakingUp is whether a destroyed ship is still coming apart.
+func (p *Particles) BreakingUp() bool {
+	return p.breakUp > 0
+}
+
+func (p *Particles) spawn(particle Particle) {
+	if p.pool.Len() >= maxParticles {
+		return
+	}
+	var slot, _ = p.pool.Add()
+	particle.Life = particle.Lifetime
+	*slot = particle
+}
+
+// Step moves the particles along with one step of the game and adds new ones
+// for what happened during it, so they pause and fast-forward with the game.
+func (p *Particles) Step(game *sim.Game, events []sim.Event) {
+	if game.Paused {
+		return
+	}
+
+	for _, event := range events {
+		switch event.Type {
+		case sim.ShotFired:
+			p.EmitMuzzleFlash(event)
+		case sim.AsteroidDestroyed, sim.SaucerDestroyed:
+			p.EmitDebris(event, 0.8, 60)
+		case sim.ShipDied:
+			p.EmitDebris(event, shipBreakUpTime, 30)
+			p.breakUp = shipBreakUpTime
+		}
+	}
+	if game.Player != nil && game.Player.Thrusting {
+		p.EmitExhaust(game.Player)
+	}
+
+	const dt = sim.TickDuration
+	p.breakUp = max(0, p.breakUp-dt)
+	for _, particle := range p.pool.Items() {
+		particle.Life -= dt
+		particle.Position = sim.WrapCoordinates(sim.Vector2Add(particle.Position, sim.Vector2Scale(particle.Velocity, dt)))
+		particle.Rotation += particle.AngularVelocity * dt
+	}
+	p.pool.RemoveIf(func(particle *Particle) bool { return particle.Life <= 0 })
+}
+
+// EmitExhaust puffs a spark out of the back of a thrusting ship.
+func (p *Particles) EmitExhaust(ship *sim.PlayerShip) {
+	var backwards = sim.Vector2Rotate(sim.NewVector2(-1, 0), sim.DegToRad(ship.Rotation+randomRange(-15, 15)))
+	p.spawn(Particle{
+		Kind:     Spark,
+		Position: sim.Vector2Add(ship.Position, sim.Vector2Scale(backwards, ship.Scale*0.3)),
+		Velocity: sim.Vector2Add(ship.Velocity, sim.Vector2Scale(backwards, randomRange(80, 140))),
+		Lifetime: randomRange(0.15, 0.3),
+		Color:    pickColor(rl.Orange, rl.Yellow),
+	})
+}
+
+// EmitMuzzleFlash sprays a few quick sparks forwards from where a shot left.
+func (p *Particles) EmitMuzzleFlash(event sim.Event) {
+	var forwards = sim.Vector2Rotate(sim.NewVector2(1, 0), sim.DegToRad(event.Rotation))
+	var muzzle = sim.Vector2Add(event.Position, sim.Vector2Scale(forwards, event.Scale/2))
+	for range 4 {
+		var direction = sim.Vector2Rotate(forwards, sim.DegToRad(randomRange(-25, 25)))
+		p.spawn(Particle{
+			Kind:     Spark,
+			Position: muzzle,
+			Velocity: sim.Vector2Add(event.Velocity, sim.Vector2Scale(direction, randomRange(100, 200))),
+			Lifetime: randomRange(0.05, 0.1),
+			Color:    pickColor(rl.White, rl.Yellow),
+		})
+	}
+}
+
+// EmitDebris breaks an outline into its edges, which fly apart from the
+// middle at up to speed and fade out over about lifetime seconds, along with
+// a burst of sparks.
+func (p *Particles) EmitDebris(event sim.Event, lifetime float32, speed float32) {
+	var transform = func(point sim.Vector2) sim.Vector2 {
+		return sim.Vector2Scale(sim.Vector2Rotate(point, sim.DegToRad(event.Rotation)), event.Scale)
+	}
+	for i := range event.Outline {
+		var start = transform(event.Outline[i])
+		var end = transform(event.Outline[(i+1)%len(event.Outline)])
+		var middle = sim.Vector2Lerp(start, end, 0.5)
+		var outwards = sim.Vector2Normalize(middle)
+		p.spawn(Particle{
+			Kind:            Segment,
+			Position:        sim.Vector2Add(event.Position, middle),
+			Velocity:        sim.Vector2Add(event.Velocity, sim.Vector2Scale(outwards, randomRange(speed/3, speed))),
+			Start:           sim.Vector2Subtract(start, middle),
+			End:             sim.Vector2Subtract(end, middle),
+			AngularVelocity: randomRange(-180, 180),
+			Lifetime:        lifetime * randomRange(0.6, 1),
+			Color:           rl.White,
+		})
+	}
+
+	for range 4 + len(event.Outline) {
+		var direction = sim.Vector2Rotate(sim.NewVector2(1, 0), sim.DegToRad(randomRange(0, 360)))
+		p.spawn(Particle{
+			Kind:     Spark,
+			Position: event.Position,
+			Velocity: sim.Vector2Add(event.Velocity, sim.Vector2Scale(direction, randomRange(speed, speed*3))),
+			Lifetime: randomRange(0.2, 0.5),
+			Color:    pickColor(rl.White, rl.Orange),
+		})
+	}
+}
+
+func (p *Particles) Draw() {
+	for _, particle := range p.pool.Items() {
+		var color = rl.Fade(particle.Color, particle.Life/particle.Lifetime)
+		switch particle.Kind {
+		case Spark:
+			// a streak as long as the distance covered in a 60th of a second
+			var tail = sim.Vector2Subtract(particle.Position, sim.Vector2Scale(particle.Velocity, 1.0/60))
+			rl.DrawLineEx(rl.Vector2(tail), rl.Vector2(particle.Position), 2, color)
+		case Segment:
+			var rotation = sim.DegToRad(particle.Rotation)
+			var start = sim.Vector2Add(particle.Position, sim.Vector2Rotate(particle.Start, rotation))
+			var end = sim.Vector2Add(particle.Position, sim.Vector2Rotate(particle.End, rotation))
+			rl.DrawLineEx(rl.Vector2(start), rl.Vector2(end), 2, color)
+		}
+	}
+}
+
+func randomRange(low float32, high float32) float32 {
+	return low + rand.Float32()*(high-low)
+}
+
+func pickColor(a rl.Color, b rl.Color) rl.Color {
+	if rand.IntN(2) == 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"SpaceDroid/sim"
+	"bytes"
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"os"
+)
+
+const lastReplayFile = "last.replay"
+
+// fastForwardSpeed is how many times faster than real time a fast-forwarded
+// replay runs.
+const fastForwardSpeed = 4
+
+type Playback struct {
+	Replay      *sim.Replay
+	Tick        int
+	Paused      bool
+	FastForward bool
+}
+
+func (p *Playback) Finished() bool {
+	return p.Tick >= len(p.Replay.Inputs)
+}
+
+func SaveRecording(data *GameData) {
+	if data.Recording == nil || len(data.Recording.Inputs) == 0 {
+		return
+	}
+
+	var path, err = ConfigPath(lastReplayFile)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "REPLAY: Failed to find config directory: %s", err.Error())
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := data.Recording.Encode(&buffer); err != nil {
+		rl.TraceLog(rl.LogWarning, "REPLAY: Failed to encode replay: %s", err.Error())
+		return
+	}
+	if err := WriteFileAtomic(path, buffer.Bytes()); err != nil {
+		rl.TraceLog(rl.LogWarning, "REPLAY: Failed to write %s: %s", path, err.Error())
+		return
+	}
+	rl.TraceLog(rl.LogInfo, "REPLAY: Saved %d steps to %s", len(data.Recording.Inputs), path)
+	data.Recording = nil
+}
+
+func LoadReplay(path string) (*sim.Replay, error) {
+	var file, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return sim.DecodeReplay(file)
+}
+
+func WatchLastReplay(data *GameData) {
+	var path, err = ConfigPath(lastReplayFile)
+	if err != nil {
+		data.MenuMessage = "No replay recorded yet"
+		return
+	}
+
+	replay, err := LoadReplay(path)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "REPLAY: Failed to load %s: %s", path, err.Error())
+		data.MenuMessage = "No replay recorded yet"
+		return
+	}
+
+	StartPlayback(data, replay)
+}
+
+func StartPlayback(data *GameData, replay *sim.Replay) {
+	data.Game = sim.NewGame(replay.Seed, replay.Options)
+	data.Playback = &Playback{Replay: replay}
+	data.Recording = nil
+	data.Accumulator = 0
+	data.Particles.Clear()
+	data.CameraEffects.Clear()
+	data.Heartbeat.Reset()
+	data.GameState = Replay
+}
+
+func StepPlayback(data *GameData) {
+	var playback = data.Playback
+	StepGame(data, playback.Replay.Inputs[playback.Tick])
+	playback.Tick++
+}
+
+func ProcessReplayState(data *GameData) {
+	var playback = data.Playback
+
+	if data.Input.Pressed(ActionPause) || data.Input.Pressed(ActionMenuConfirm) {
+		playback.Paused = !playback.Paused
+	}
+
+	if rl.IsKeyPressed(rl.KeyF) {
+		playback.FastForward = !playback.FastForward
+	}
+
+	var alpha float32 = 1
+	if playback.Paused {
+		data.Accumulator = 0
+		if rl.IsKeyPressed(rl.KeyN) && !playback.Finished() {
+			StepPlayback(data)
+		}
+	} else {
+		var frameTime = GameFrameTime(data)
+		if playback.FastForward {
+			frameTime *= fastForwardSpeed
+		}
+
+		data.Accumulator += frameTime
+		for data.Accumulator >= sim.TickDuration && !playback.Finished() {
+			StepPlayback(data)
+			data.Accumulator -= sim.TickDuration
+		}
+		if !playback.Finished() {
+			alpha = data.Accumulator / sim.TickDuration
+		}
+	}
+
+	DrawGame(data, alpha)
+
+	var status = fmt.Sprintf("REPLAY %.1fs / %.1fs", float32(playback.Tick)*sim.TickDuration, float32(len(playback.Replay.Inputs))*sim.TickDuration)
+	if playback.FastForward {
+		status += fmt.Sprintf("  x%d", fastForwardSpeed)
+	}
+	if playback.Paused {
+		status += "  PAUSED"
+	}
+	if playback.Finished() {
+		status += "  END"
+	}
+	DrawTextCenter(status, 10, 16, rl.Green)
+
+	var controls = &data.Settings.Controls
+	DrawTextCenter(fmt.Sprintf("'%s' PAUSE   'N' STEP   'F' FAST-FORWARD   '%s' MENU", controls.Primary(ActionPause), controls.Primary(ActionMenuBack)), screenHeight-24, 16, rl.Green)
+
+	if data.Input.Pressed(ActionMenuBack) {
+		data.Playback = nil
+		data.CameraEffects.Clear()
+		data.GameState = Menu
+	}
+}
@@ -0,0 +1,97 @@
+package scores
+
+import (
+	"SpaceDroid/sim"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// MaxScores is how many scores each level keeps.
+const MaxScores = 10
+
+type HighScore struct {
+	Initials string    `json:"initials"`
+	Score    int32     `json:"score"`
+	Wave     int32     `json:"wave"`
+	Date     time.Time `json:"date"`
+	Mode     string    `json:"mode"`
+	// Level is the name of the level played, since a score only compares with
+	// others on the same level
+	Level string `json:"level"`
+}
+
+// Table keeps the best MaxScores scores of each level.
+type Table struct {
+	Scores []HighScore `json:"scores"`
+}
+
+// Decode reads a table saved as JSON, sorted and trimmed as if every score
+// had gone through Insert.
+func Decode(contents []byte) (*Table, error) {
+	var table = &Table{}
+	if err := json.Unmarshal(contents, table); err != nil {
+		return nil, err
+	}
+
+	// scores from before levels were all on the classic one
+	for i := range table.Scores {
+		if table.Scores[i].Level == "" {
+			table.Scores[i].Level = sim.ClassicLevel.Name
+		}
+	}
+	table.sort()
+	return table, nil
+}
+
+// sort orders the scores from best to worst, keeping equal scores in the
+// order they were set, and drops any past the top MaxScores of their level.
+func (t *Table) sort() {
+	slices.SortStableFunc(t.Scores, func(a, b HighScore) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	var counts = map[string]int{}
+	t.Scores = slices.DeleteFunc(t.Scores, func(score HighScore) bool {
+		counts[score.Level]++
+		return counts[score.Level] > MaxScores
+	})
+}
+
+// Level lists the scores of a level, best first.
+func (t *Table) Level(level string) []HighScore {
+	var scores []HighScore
+	for _, score := range t.Scores {
+		if score.Level == level {
+			scores = append(scores, score)
+		}
+	}
+	return scores
+}
+
+// Qualifies reports whether score would make the level's table. Matching the
+// lowest score of a full table isn't enough, since the older one stays.
+func (t *Table) Qualifies(level string, score int32) bool {
+	if score <= 0 {
+		return false
+	}
+	var scores = t.Level(level)
+	return len(scores) < MaxScores || score > scores[len(scores)-1].Score
+}
+
+// Insert adds the score to the table and returns its rank within its level,
+// starting at 0. It goes below any equal scores already there.
+func (t *Table) Insert(score HighScore) int {
+	var scores = t.Level(score.Level)
+	var rank = len(scores)
+	for i, existing := range scores {
+		if score.Score > existing.Score {
+			rank = i
+			break
+		}
+	}
+
+	t.Scores = append(t.Scores, score)
+	t.sort()
+	return rank
+}
@@ -0,0 +1,111 @@
+package scores
+
+import (
+	"SpaceDroid/sim"
+	"testing"
+)
+
+// fill makes a table of level's scores from best to worst, named by their
+// order.
+func fill(table *Table, level string, points ...int32) {
+	for i, score := range points {
+		table.Insert(HighScore{Initials: string(rune('A' + i)), Score: score, Level: level})
+	}
+}
+
+func TestQualifies(t *testing.T) {
+	var full = &Table{}
+	fill(full, "Classic", 100, 90, 80, 70, 60, 50, 40, 30, 20, 10)
+	var short = &Table{}
+	fill(short, "Classic", 100, 90)
+
+	var tests = []struct {
+		name    string
+		table   *Table
+		level   string
+		score   int32
+		qualify bool
+	}{
+		{"empty table", &Table{}, "Classic", 1, true},
+		{"nothing scored", &Table{}, "Classic", 0, false},
+		{"room left", short, "Classic", 5, true},
+		{"beats the lowest", full, "Classic", 11, true},
+		{"ties the lowest", full, "Classic", 10, false},
+		{"below the lowest", full, "Classic", 5, false},
+		{"other level is empty", full, "Swarm", 5, true},
+	}
+	for _, test := range tests {
+		if qualify := test.table.Qualifies(test.level, test.score); qualify != test.qualify {
+			t.Errorf("%s: qualifies = %v, want %v", test.name, qualify, test.qualify)
+		}
+	}
+}
+
+func TestInsert(t *testing.T) {
+	var tests = []struct {
+		name     string
+		existing []int32
+		score    int32
+		rank     int
+	}{
+		{"first", nil, 50, 0},
+		{"best", []int32{40, 30}, 50, 0},
+		{"between", []int32{60, 40}, 50, 1},
+		{"last", []int32{60, 40}, 30, 2},
+		{"below an equal score", []int32{60, 50, 40}, 50, 2},
+	}
+	for _, test := range tests {
+		var table = &Table{}
+		fill(table, "Classic", test.existing...)
+		if rank := table.Insert(HighScore{Initials: "NEW", Score: test.score, Level: "Classic"}); rank != test.rank {
+			t.Errorf("%s: rank %d, want %d", test.name, rank, test.rank)
+		}
+		if scores := table.Level("Classic"); scores[test.rank].Initials != "NEW" {
+			t.Errorf("%s: rank %d holds %+v", test.name, test.rank, scores[test.rank])
+		}
+	}
+}
+
+func TestTablePerLevel(t *testing.T) {
+	var table = &Table{}
+	for i := range int32(MaxScores + 3) {
+		table.Insert(HighScore{Score: 100 + i, Level: "Classic"})
+		table.Insert(HighScore{Score: 200 + i, Level: "Swarm"})
+	}
+	table.Insert(HighScore{Score: 1, Level: "Sprint"})
+
+	for _, test := range []struct {
+		level  string
+		count  int
+		best   int32
+		lowest int32
+	}{
+		{"Classic", MaxScores, 112, 103},
+		{"Swarm", MaxScores, 212, 203},
+		{"Sprint", 1, 1, 1},
+	} {
+		var scores = table.Level(test.level)
+		if len(scores) != test.count || scores[0].Score != test.best || scores[len(scores)-1].Score != test.lowest {
+			t.Errorf("%s kept %v, want %d from %d down to %d", test.level, scores, test.count, test.best, test.lowest)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var table, err = Decode([]byte(`{"scores": [
+		{"initials": "OLD", "score": 10},
+		{"initials": "NEW", "score": 30, "level": "Classic"},
+		{"initials": "SWM", "score": 20, "level": "Swarm"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var classic = table.Level(sim.ClassicLevel.Name)
+	if len(classic) != 2 || classic[0].Initials != "NEW" || classic[1].Initials != "OLD" {
+		t.Errorf("classic scores %v, want NEW then the one saved without a level", classic)
+	}
+
+	if _, err := Decode([]byte(`{"scores": [{"score": "lots"}]}`)); err == nil {
+		t.Errorf("malformed table decoded without an error")
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"io/fs"
+	"math"
+	"os"
+	"slices"
+)
+
+const settingsFile = "settings.json"
+
+type WindowSize struct {
+	Width  int32 `json:"width"`
+	Height int32 `json:"height"`
+}
+
+// WindowSizes all share the 16:9 shape of the play field.
+var WindowSizes = []WindowSize{{800, 450}, {1280, 720}, {1600, 900}, {1920, 1080}}
+
+// HyperspaceRiskOptions are the percentage chances of breaking up on leaving
+// hyperspace on offer.
+var HyperspaceRiskOptions = []uint8{0, 10, 25, 50}
+
+// TargetFPSOptions are the frame rate caps on offer, 0 meaning uncapped.
+var TargetFPSOptions = []int32{30, 60, 120, 144, 0}
+
+type Settings struct {
+	MasterVolume float32    `json:"masterVolume"`
+	SfxVolume    float32    `json:"sfxVolume"`
+	MusicVolume  float32    `json:"musicVolume"`
+	Fullscreen   bool       `json:"fullscreen"`
+	WindowSize   WindowSize `json:"windowSize"`
+	TargetFPS    int32      `json:"targetFps"`
+	VSync        bool       `json:"vsync"`
+
+	// AsteroidPhysics starts new games with asteroids that bounce off each
+	// other and carry momentum, instead of passing through one another.
+	AsteroidPhysics bool  `json:"asteroidPhysics"`
+	HyperspaceRisk  uint8 `json:"hyperspaceRisk"`
+
+	// Motion tones down or turns off screen shake, hit-stop and zoom
+	Motion MotionLevel `json:"motion"`
+
+	DrawBoundingBoxes bool `json:"drawBoundingBoxes"`
+	DrawAsteroidInfo  bool `json:"drawAsteroidInfo"`
+	DrawStats         bool `json:"drawStats"`
+
+	Controls Controls `json:"controls"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		MasterVolume:   1,
+		SfxVolume:      1,
+		MusicVolume:    0.7,
+		WindowSize:     WindowSizes[0],
+		TargetFPS:      60,
+		VSync:          true,
+		HyperspaceRisk: 10,
+		Motion:         MotionFull,
+		Controls:       DefaultControls(),
+	}
+}
+
+// LoadSettings reads each setting on its own so one missing or malformed
+// value falls back to its default without throwing away the rest.
+func LoadSettings() Settings {
+	var settings = DefaultSettings()
+
+	var path, err = ConfigPath(settingsFile)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Failed to find config directory: %s", err.Error())
+		return settings
+	}
+
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings
+	}
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Failed to read %s: %s", path, err.Error())
+		return settings
+	}
+
+	settings, err = DecodeSettings(contents)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Ignoring malformed %s: %s", path, err.Error())
+	}
+	return settings
+}
+
+// DecodeSettings reads settings saved as JSON, falling back to the default of
+// any missing, malformed or out of range value. A file that isn't a JSON
+// object at all gives the defaults and an error.
+func DecodeSettings(contents []byte) (Settings, error) {
+	var settings = DefaultSettings()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(contents, &fields); err != nil {
+		return settings, err
+	}
+
+	loadSetting(fields, "masterVolume", &settings.MasterVolume)
+	loadSetting(fields, "sfxVolume", &settings.SfxVolume)
+	loadSetting(fields, "musicVolume", &settings.MusicVolume)
+	loadSetting(fields, "fullscreen", &settings.Fullscreen)
+	loadSetting(fields, "windowSize", &settings.WindowSize)
+	loadSetting(fields, "targetFps", &settings.TargetFPS)
+	loadSetting(fields, "vsync", &settings.VSync)
+	loadSetting(fields, "asteroidPhysics", &settings.AsteroidPhysics)
+	loadSetting(fields, "hyperspaceRisk", &settings.HyperspaceRisk)
+	loadSetting(fields, "motion", &settings.Motion)
+	loadSetting(fields, "drawBoundingBoxes", &settings.DrawBoundingBoxes)
+	loadSetting(fields, "drawAsteroidInfo", &settings.DrawAsteroidInfo)
+	loadSetting(fields, "drawStats", &settings.DrawStats)
+	loadSetting(fields, "controls", &settings.Controls)
+
+	settings.Validate()
+	return settings, nil
+}
+
+func loadSetting[T any](fields map[string]json.RawMessage, name string, value *T) {
+	var raw, ok = fields[name]
+	if !ok {
+		return
+	}
+
+	var parsed T
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Using default for malformed %s: %s", name, err.Error())
+		return
+	}
+	*value = parsed
+}
+
+// Validate replaces any out of range value with its default.
+func (s *Settings) Validate() {
+	var defaults = DefaultSettings()
+
+	var validVolume = func(name string, volume *float32, fallback float32) {
+		if math.IsNaN(float64(*volume)) || *volume < 0 || *volume > 1 {
+			rl.TraceLog(rl.LogWarning, "SETTINGS: %s %f is out of range, using %f", name, *volume, fallback)
+			*volume = fallback
+		}
+	}
+	validVolume("masterVolume", &s.MasterVolume, defaults.MasterVolume)
+	validVolume("sfxVolume", &s.SfxVolume, defaults.SfxVolume)
+	validVolume("musicVolume", &s.MusicVolume, defaults.MusicVolume)
+
+	if !slices.Contains(WindowSizes, s.WindowSize) {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Unsupported window size %dx%d, using %dx%d", s.WindowSize.Width, s.WindowSize.Height, defaults.WindowSize.Width, defaults.WindowSize.Height)
+		s.WindowSize = defaults.WindowSize
+	}
+
+	if !slices.Contains(TargetFPSOptions, s.TargetFPS) {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Unsupported target FPS %d, using %d", s.TargetFPS, defaults.TargetFPS)
+		s.TargetFPS = defaults.TargetFPS
+	}
+
+	if !slices.Contains(HyperspaceRiskOptions, s.HyperspaceRisk) {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Unsupported hyperspace risk %d%%, using %d%%", s.HyperspaceRisk, defaults.HyperspaceRisk)
+		s.HyperspaceRisk = defaults.HyperspaceRisk
+	}
+
+	if !slices.Contains(MotionLevels, s.Motion) {
+		rl.TraceLog(rl.LogWarning, "SETTINGS: Unsupported motion level %q, using %q", s.Motion, defaults.Motion)
+		s.Motion = defaults.Motion
+	}
+
+	s.Controls.Validate()
+}
+
+func (s *Settings) Save() error {
+	var path, err = ConfigPath(settingsFile)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(path, contents)
+}
+
+// ApplySettings pushes the current settings to the window and audio device.
+// The window is only resized or switched to fullscreen when that changed from
+// previous, nil for settings never applied. VSync can only be set as the
+// window is created, so it takes effect on the next start.
+func ApplySettings(data *GameData, previous *Settings) {
+	var settings = &data.Settings
+
+	rl.SetMasterVolume(settings.MasterVolume)
+	data.Audio.SetVolume(settings.SfxVolume)
+
+	if previous == nil || previous.Fullscreen != settings.Fullscreen || previous.WindowSize != settings.WindowSize {
+		if settings.Fullscreen != rl.IsWindowFullscreen() {
+			if settings.Fullscreen {
+				var monitor = rl.GetCurrentMonitor()
+				rl.SetWindowSize(rl.GetMonitorWidth(monitor), rl.GetMonitorHeight(monitor))
+			}
+			rl.ToggleFullscreen()
+		}
+		if !settings.Fullscreen {
+			rl.SetWindowSize(int(settings.WindowSize.Width), int(settings.WindowSize.Height))
+		}
+	}
+
+	rl.SetTargetFPS(settings.TargetFPS)
+}
+
+// UpdateCamera scales the fixed size play field to fit the window, centred
+// with black bars if the shapes differ, then adds any shake and zoom.
+func UpdateCamera(data *GameData) {
+	var width = float32(rl.GetScreenWidth())
+	var height = float32(rl.GetScreenHeight())
+	var zoom = min(width/screenWidth, height/screenHeight)
+
+	data.Viewport = rl.NewRectangle((width-screenWidth*zoom)/2, (height-screenHeight*zoom)/2, screenWidth*zoom, screenHeight*zoom)
+	data.Camera = rl.NewCamera2D(rl.NewVector2(data.Viewport.X, data.Viewport.Y), rl.Vector2Zero(), 0, zoom)
+	data.CameraEffects.Apply(&data.Camera)
+}
+
+type OptionItem struct {
+	Label  func(settings *Settings) string
+	Change func(settings *Settings, direction int)
+}
+
+func onOff(value bool) string {
+	if value {
+		return "On"
+	}
+	return "Off"
+}
+
+func volumeOption(name string, volume func(settings *Settings) *float32) OptionItem {
+	return OptionItem{
+		Label: func(settings *Settings) string {
+			return fmt.Sprintf("%s: %d%%", name, int32(math.Round(float64(*volume(settings)*100))))
+		},
+		Change: func(settings *Settings, direction int) {
+			var value = volume(settings)
+			*value = float32(math.Round(float64(*value*10+float32(direction)))) / 10
+			*value = max(0, min(1, *value))
+		},
+	}
+}
+
+func toggleOption(name string, value func(settings *Settings) *bool) OptionItem {
+	return OptionItem{
+		Label: func(settings *Settings) string {
+			return name + ": " + onOff(*value(settings))
+		},
+		Change: func(settings *Settings, direction int) {
+			*value(settings) = !*value(settings)
+		},
+	}
+}
+
+// cycle steps through options in the given direction, wrapping at both ends.
+func cycle[T comparable](options []T, current T, direction int) T {
+	var index = max(0, slices.Index(options, current))
+	return options[(index+direction+len(options))%len(options)]
+}
+
+var optionItems = []OptionItem{
+	volumeOption("Master Volume", func(s *Settings) *float32 { return &s.MasterVolume }),
+	volumeOption("SFX Volume", func(s *Settings) *float32 { return &s.SfxVolume }),
+	volumeOption("Music Volume", func(s *Settings) *float32 { return &s.MusicVolume }),
+	toggleOption("Fullscreen", func(s *Settings) *bool { return &s.Fullscreen }),
+	{
+		Label: func(settings *Settings) string {
+			return fmt.Sprintf("Window Size: %dx%d", settings.WindowSize.Width, settings.WindowSize.Height)
+		},
+		Change: func(settings *Settings, direction int) {
+			settings.WindowSize = cycle(WindowSizes, settings.WindowSize, direction)
+		},
+	},
+	{
+		Label: func(settings *Settings) string {
+			if settings.TargetFPS == 0 {
+				return "Target FPS: Unlimited"
+			}
+			return fmt.Sprintf("Target FPS: %d", settings.TargetFPS)
+		},
+		Change: func(settings *Settings, direction int) {
+			settings.TargetFPS = cycle(TargetFPSOptions, settings.TargetFPS, direction)
+		},
+	},
+	toggleOption("VSync (on restart)", func(s *Settings) *bool { return &s.VSync }),
+	{
+		Label: func(settings *Settings) string {
+			return "Screen Motion: " + settings.Motion.Name()
+		},
+		Change: func(settings *Settings, direction int) {
+			settings.Motion = cycle(MotionLevels, settings.Motion, direction)
+		},
+	},
+	toggleOption("Asteroid Physics", func(s *Settings) *bool { return &s.AsteroidPhysics }),
+	{
+		Label: func(settings *Settings) string {
+			return fmt.Sprintf("Hyperspace Risk: %d%%", settings.HyperspaceRisk)
+		},
+		Change: func(settings *Settings, direction int) {
+			settings.HyperspaceRisk = cycle(HyperspaceRiskOptions, settings.HyperspaceRisk, direction)
+		},
+	},
+	toggleOption("Bounding Boxes", func(s *Settings) *bool { return &s.DrawBoundingBoxes }),
+	toggleOption("Asteroid Info", func(s *Settings) *bool { return &s.DrawAsteroidInfo }),
+	toggleOption("Stats", func(s *Settings) *bool { return &s.DrawStats }),
+}
+
+func ProcessOptionsState(data *GameData) {
+	DrawTextCenter("OPTIONS", 20, 42, rl.Green)
+
+	var controlsIndex = len(optionItems)
+	var backIndex = len(optionItems) + 1
+	var count = len(optionItems) + 2
+	var y float32 = 80
+	for i, item := range optionItems {
+		DrawMenuItem(item.Label(&data.Settings), y, data.OptionIndex == i)
+		y += 22
+	}
+	DrawMenuItem("Controls", y, data.OptionIndex == controlsIndex)
+	DrawMenuItem("Back", y+34, data.OptionIndex == backIndex)
+
+	var input = &data.Input
+	if input.Pressed(ActionMenuDown) {
+		data.OptionIndex = (data.OptionIndex + 1) % count
+	}
+	if input.Pressed(ActionMenuUp) {
+		data.OptionIndex = (data.OptionIndex + count - 1) % count
+	}
+
+	var direction = 0
+	if input.Pressed(ActionMenuRight) {
+		direction = 1
+	}
+	if input.Pressed(ActionMenuLeft) {
+		direction = -1
+	}
+
+	var back = input.Pressed(ActionMenuBack)
+	if input.Pressed(ActionMenuConfirm) {
+		switch data.OptionIndex {
+		case controlsIndex:
+			data.ControlsIndex = 0
+			data.GameState = ControlsMenu
+		case backIndex:
+			back = true
+		default:
+			direction = 1
+		}
+	}
+
+	if direction != 0 && data.OptionIndex < len(optionItems) {
+		var previous = data.Settings
+		optionItems[data.OptionIndex].Change(&data.Settings, direction)
+		ApplySettings(data, &previous)
+	}
+
+	if back {
+		if err := data.Settings.Save(); err != nil {
+			rl.TraceLog(rl.LogWarning, "SETTINGS: Failed to save settings: %s", err.Error())
+		}
+		data.GameState = Menu
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeSettings(t *testing.T) {
+	var defaults = DefaultSettings()
+	var tests = []struct {
+		name   string
+		json   string
+		change func(settings *Settings)
+	}{
+		{"empty", `{}`, nil},
+		{"partial", `{"sfxVolume": 0.5, "fullscreen": true}`, func(s *Settings) {
+			s.SfxVolume = 0.5
+			s.Fullscreen = true
+		}},
+		{"unknown field", `{"brightness": 3, "drawStats": true}`, func(s *Settings) {
+			s.DrawStats = true
+		}},
+		{"malformed values", `{"masterVolume": "loud", "vsync": 1, "windowSize": [1280, 720], "musicVolume": 0.2}`, func(s *Settings) {
+			s.MusicVolume = 0.2
+		}},
+		{"out of range", `{"masterVolume": 1.5, "sfxVolume": -0.1, "targetFps": 75, "hyperspaceRisk": 33, "motion": "wobbly", "windowSize": {"width": 1000, "height": 10}}`, nil},
+		{"supported choices", `{"targetFps": 0, "hyperspaceRisk": 50, "motion": "off", "windowSize": {"width": 1920, "height": 1080}}`, func(s *Settings) {
+			s.TargetFPS = 0
+			s.HyperspaceRisk = 50
+			s.Motion = MotionOff
+			s.WindowSize = WindowSize{1920, 1080}
+		}},
+		{"controls", `{"controls": {"Fire": [{"kind": "key", "code": 90}, {"kind": "key", "code": -4}], "Menu Back": []}}`, func(s *Settings) {
+			s.Controls[ActionFire] = []Binding{Key(90)}
+		}},
+	}
+	for _, test := range tests {
+		var want = DefaultSettings()
+		if test.change != nil {
+			test.change(&want)
+		}
+		var settings, err = DecodeSettings([]byte(test.json))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(settings, want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, settings, want)
+		}
+	}
+
+	for _, contents := range []string{``, `[1, 2]`, `{"sfxVolume": 0.5`} {
+		var settings, err = DecodeSettings([]byte(contents))
+		if err == nil {
+			t.Errorf("%q decoded without an error", contents)
+		}
+		if !reflect.DeepEqual(settings, defaults) {
+			t.Errorf("%q didn't fall back to the defaults", contents)
+		}
+	}
+}
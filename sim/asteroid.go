@@ -0,0 +1,217 @@
+package sim
+
+import "math"
+
+type AsteroidSize int32
+
+func (as AsteroidSize) Name() string {
+	switch as {
+	case Small:
+		return "Small"
+	case Medium:
+		return "Medium"
+	case Large:
+		return "Large"
+	}
+
+	return "Unknown"
+}
+
+// Points is the score for destroying an asteroid of this size, smaller ones
+// being harder to hit are worth more.
+func (as AsteroidSize) Points() int32 {
+	switch as {
+	case Small:
+		return 100
+	case Medium:
+		return 50
+	case Large:
+		return 20
+	}
+
+	return 0
+}
+
+const (
+	Small AsteroidSize = iota
+	Medium
+	Large
+)
+
+// asteroidMaxPoints is the most corners an asteroid outline can have, which
+// is also the most convex pieces it can be split into.
+const asteroidMaxPoints = 10
+
+// asteroidMaxSpin is the fastest a new asteroid tumbles, in degrees per second
+// either way.
+const asteroidMaxSpin = 90
+
+type Asteroid struct {
+	Position     Vector2
+	PrevPosition Vector2
+	// Rotation is how far the asteroid has spun, which has nothing to do with
+	// the way it is moving
+	Rotation     float32
+	PrevRotation float32
+	Scale        float32
+	// Velocity is in units per second and AngularVelocity in degrees per second
+	Velocity        Vector2
+	AngularVelocity float32
+	// Mass is only used with the asteroid physics option
+	Mass         float32
+	Size         AsteroidSize
+	ShouldDelete bool
+	RenderPoints []Vector2
+	// Pieces are the convex parts of the outline used for collision
+	Pieces [][]Vector2
+	Radius float32
+
+	// collisionPieces are the pieces in world space, refreshed once a step by
+	// UpdateCollisionPieces rather than for every pair tested
+	collisionPieces [][]Vector2
+	wrappedPieces   [][]Vector2
+	scaledPoints    []Vector2
+}
+
+// GetScaledRenderPoints is the outline in world space. The slice is reused by
+// the next call.
+func (a *Asteroid) GetScaledRenderPoints() []Vector2 {
+	return a.GetScaledRenderPointsOffset(Vector2Zero())
+}
+
+// GetScaledRenderPointsOffset is the outline of the copy of the asteroid moved
+// by offset, used for the copies drawn across the screen edges.
+func (a *Asteroid) GetScaledRenderPointsOffset(offset Vector2) []Vector2 {
+	var position = Vector2Add(a.Position, offset)
+	var transform = func(point Vector2) Vector2 {
+		return Vector2Add(Vector2Scale(Vector2Rotate(point, DegToRad(a.Rotation)), a.Scale), position)
+	}
+	a.scaledPoints = a.scaledPoints[:0]
+	for _, point := range a.RenderPoints {
+		a.scaledPoints = append(a.scaledPoints, transform(point))
+	}
+
+	return a.scaledPoints
+}
+
+func (a *Asteroid) UpdateCollisionPieces() {
+	a.collisionPieces = TransformPieces(a.collisionPieces, a.Pieces, a.Position, a.Rotation, a.Scale)
+}
+
+// CollidesWrapped tests the asteroid and its copies across the screen edges
+// against the convex pieces of another shape, whose radius decides how close
+// to an edge counts. UpdateCollisionPieces must have been called since the
+// asteroid last moved. The contact normal points away from the asteroid.
+func (a *Asteroid) CollidesWrapped(pieces [][]Vector2, radius float32) (Contact, bool) {
+	return collideWrapped(a.collisionPieces, a.Position, a.Radius, pieces, radius, &a.wrappedPieces)
+}
+
+// SweepWrapped finds where the segment from start to end first hits the
+// asteroid or one of its copies across the screen edges.
+func (a *Asteroid) SweepWrapped(start Vector2, end Vector2) (Sweep, bool) {
+	return sweepWrapped(start, end, a.collisionPieces, a.Position, a.Radius, &a.wrappedPieces)
+}
+
+func (a *Asteroid) GetBoundingBox() Rectangle {
+	var transform = func(point Vector2) Vector2 {
+		return Vector2Add(Vector2Scale(Vector2Rotate(point, DegToRad(a.Rotation)), a.Scale), a.Position)
+	}
+	var left float32 = 0
+	var right float32 = 0
+	var up float32 = 0
+	var down float32 = 0
+	for _, point := range a.RenderPoints {
+		if point.X < left {
+			left = point.X
+		}
+		if point.X > right {
+			right = point.X
+		}
+
+		if point.Y > up {
+			up = point.Y
+		}
+		if point.Y < down {
+			down = point.Y
+		}
+	}
+
+	var width = left - right
+	var height = up - down
+	var pos = transform(NewVector2(left+height/2, up+width/2))
+	return NewRectangle(pos.X, pos.Y, width*a.Scale, height*a.Scale)
+}
+
+func (a *Asteroid) GenerateAsteroid(rng *Rng) {
+	var numPoints = rng.GetRandomValue(6, asteroidMaxPoints)
+	var points = a.RenderPoints[:0]
+	var sections = float32(360 / numPoints)
+	var r float32 = 0
+	for range numPoints {
+		var pos = NewVector2(float32(math.Cos(float64(DegToRad(r)))), float32(math.Sin(float64(DegToRad(r)))))
+		pos = Vector2Normalize(pos)
+		var offset1 = float32(rng.GetRandomValue(-1, int32(a.Scale))) / a.Scale
+		var offset2 = float32(rng.GetRandomValue(-1, int32(a.Scale))) / a.Scale
+		pos = Vector2Add(pos, NewVector2(offset1, offset2))
+		r += sections
+
+		points = append(points, NewVector2(pos.X, pos.Y))
+	}
+	a.RenderPoints = points
+	a.Pieces = DecomposeInto(a.Pieces, points)
+	a.Radius = BoundingRadius(points, a.Scale)
+	a.UpdateMass()
+}
+
+func (a *Asteroid) GetScaleForSize() float32 {
+	switch a.Size {
+	case Small:
+		return 8
+	case Medium:
+		return 15
+	case Large:
+		return 24
+	}
+
+	return 8
+}
+
+// Heading is the direction the asteroid is moving in, in degrees.
+func (a *Asteroid) Heading() float32 {
+	return float32(RadToDeg(math.Atan2(float64(a.Velocity.Y), float64(a.Velocity.X))))
+}
+
+// Init resets an asteroid moving along heading at speed with a new random
+// outline, spin angle and spin. One reused from a pool keeps its slices to
+// fill in again.
+func (a *Asteroid) Init(rng *Rng, position Vector2, heading float32, size AsteroidSize, speed float32) {
+	var rotation = rng.GetRandomValueF(0, 359)
+	*a = Asteroid{
+		Position: position, PrevPosition: position, Rotation: rotation, PrevRotation: rotation, Size: size,
+		AngularVelocity: rng.GetRandomValueF(-asteroidMaxSpin, asteroidMaxSpin),
+		RenderPoints:    a.RenderPoints, Pieces: a.Pieces,
+		collisionPieces: a.collisionPieces, wrappedPieces: a.wrappedPieces, scaledPoints: a.scaledPoints,
+	}
+	if a.RenderPoints == nil {
+		a.AllocateBuffers()
+	}
+	a.Scale = a.GetScaleForSize()
+	a.Velocity = Vector2Scale(Vector2Rotate(NewVector2(1, 0), DegToRad(heading)), speed)
+	a.GenerateAsteroid(rng)
+}
+
+// AllocateBuffers sizes the asteroid's slices for the largest outline up
+// front, so reusing it for any other asteroid never has to grow them.
+func (a *Asteroid) AllocateBuffers() {
+	a.RenderPoints = make([]Vector2, 0, asteroidMaxPoints)
+	a.scaledPoints = make([]Vector2, 0, asteroidMaxPoints)
+	a.Pieces = makePieces(asteroidMaxPoints, asteroidMaxPoints)
+	a.collisionPieces = makePieces(asteroidMaxPoints, asteroidMaxPoints)
+	a.wrappedPieces = makePieces(asteroidMaxPoints, asteroidMaxPoints)
+}
+
+func NewAsteroid(rng *Rng, position Vector2, heading float32, size AsteroidSize, speed float32) *Asteroid {
+	var a = &Asteroid{}
+	a.Init(rng, position, heading, size, speed)
+	return a
+}
@@ -0,0 +1,96 @@
+package sim
+
+import "math"
+
+// BroadphaseCellSize is a little over the largest asteroid's bounding radius,
+// so most asteroids only land in a handful of cells.
+const BroadphaseCellSize = 64
+
+// SpatialHash is a grid over the wrapping world used to narrow down which
+// things could be touching before the exact polygon tests. Each thing goes in
+// every cell its bounding circle touches, wrapping round the world edges.
+type SpatialHash struct {
+	Columns    int
+	Rows       int
+	CellWidth  float32
+	CellHeight float32
+
+	cells [][]int
+	// seen holds the stamp of the last query each id was returned from, so an
+	// id spread over several cells is only returned once per query
+	seen    []uint32
+	stamp   uint32
+	results []int
+}
+
+func NewSpatialHash(cellSize float32) *SpatialHash {
+	var columns = max(1, int(WorldWidth/cellSize))
+	var rows = max(1, int(WorldHeight/cellSize))
+	var cells = make([][]int, columns*rows)
+	for i := range cells {
+		cells[i] = make([]int, 0, 16)
+	}
+	return &SpatialHash{
+		Columns:    columns,
+		Rows:       rows,
+		CellWidth:  WorldWidth / float32(columns),
+		CellHeight: WorldHeight / float32(rows),
+		cells:      cells,
+	}
+}
+
+// Clear empties the grid, keeping its memory for the next step.
+func (h *SpatialHash) Clear() {
+	for i := range h.cells {
+		h.cells[i] = h.cells[i][:0]
+	}
+}
+
+func (h *SpatialHash) Insert(id int, position Vector2, radius float32) {
+	if id >= len(h.seen) {
+		h.seen = append(h.seen, make([]uint32, id+1-len(h.seen))...)
+	}
+	h.forEachCell(position, radius, func(cell int) {
+		h.cells[cell] = append(h.cells[cell], id)
+	})
+}
+
+// Query returns the ids of everything that might overlap the circle. The
+// order depends only on what was inserted, so it is deterministic. The slice
+// is reused by the next query.
+func (h *SpatialHash) Query(position Vector2, radius float32) []int {
+	h.stamp++
+	h.results = h.results[:0]
+	h.forEachCell(position, radius, func(cell int) {
+		for _, id := range h.cells[cell] {
+			if h.seen[id] != h.stamp {
+				h.seen[id] = h.stamp
+				h.results = append(h.results, id)
+			}
+		}
+	})
+	return h.results
+}
+
+func (h *SpatialHash) forEachCell(position Vector2, radius float32, fn func(cell int)) {
+	var x0, x1 = cellSpan(position.X-radius, position.X+radius, h.CellWidth, h.Columns)
+	var y0, y1 = cellSpan(position.Y-radius, position.Y+radius, h.CellHeight, h.Rows)
+	for y := y0; y <= y1; y++ {
+		var row = (y%h.Rows + h.Rows) % h.Rows
+		for x := x0; x <= x1; x++ {
+			var column = (x%h.Columns + h.Columns) % h.Columns
+			fn(row*h.Columns + column)
+		}
+	}
+}
+
+// cellSpan is the range of cells from low to high, which may run off either
+// end of the grid to be wrapped later, but never covers a cell twice.
+func cellSpan(low float32, high float32, cellSize float32, count int) (int, int) {
+	var first = int(math.Floor(float64(low / cellSize)))
+	var last = int(math.Floor(float64(high / cellSize)))
+	if last-first >= count {
+		return 0, count - 1
+	}
+	return first, last
+}
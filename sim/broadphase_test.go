@@ -0,0 +1,46 @@
+package sim
+
+import (
+	"fmt"
+	"testing"
+)
+
+// BenchmarkProcessCollision fills the world with as many bullets as asteroids.
+// Two steps run in each 60 FPS frame, so a step has to stay under about 8ms.
+func BenchmarkProcessCollision(b *testing.B) {
+	for _, count := range []int{100, 1000, 2000} {
+		b.Run(fmt.Sprintf("%d", count), func(b *testing.B) {
+			var g = NewGame(1, Options{})
+			var rng = NewRng(2)
+			g.Asteroids.Clear()
+			for range count {
+				var position = NewVector2(float32(rng.GetRandomValue(0, int32(WorldWidth)-1)), float32(rng.GetRandomValue(0, int32(WorldHeight)-1)))
+				var a, _ = g.Asteroids.Add()
+				a.Init(rng, position, rng.GetRandomAngle(), Small, 60)
+			}
+			for range count {
+				var position = NewVector2(float32(rng.GetRandomValue(0, int32(WorldWidth)-1)), float32(rng.GetRandomValue(0, int32(WorldHeight)-1)))
+				var bullet, _ = g.Bullets.Add()
+				bullet.Init(position, 10, rng.GetRandomAngle(), 480, 1)
+			}
+
+			b.ResetTimer()
+			for range b.N {
+				// drop the fragments split off by the last run
+				var kept = 0
+				g.Asteroids.RemoveIf(func(a *Asteroid) bool {
+					kept++
+					return kept > count
+				})
+				for _, a := range g.Asteroids.Items() {
+					a.ShouldDelete = false
+				}
+				for _, bullet := range g.Bullets.Items() {
+					bullet.ShouldDelete = false
+				}
+				g.events = g.events[:0]
+				g.ProcessCollision()
+			}
+		})
+	}
+}
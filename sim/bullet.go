@@ -0,0 +1,34 @@
+package sim
+
+import "math"
+
+type Bullet struct {
+	Position     Vector2
+	PrevPosition Vector2
+	Scale        float32
+	Rotation     float32
+	// Speed is in units per second
+	Speed        float32
+	Lifetime     float32
+	ShouldDelete bool
+	Radius       float32
+	// FromSaucer marks shots fired at the ship, which score nothing
+	FromSaucer bool
+}
+
+func (b Bullet) GetBoundingBox() Rectangle {
+	return NewRectangle(b.Position.X, b.Position.Y, b.Scale, b.Scale)
+}
+
+// Init resets a bullet, which may be one reused from a pool.
+func (b *Bullet) Init(position Vector2, scale float32, rotation float32, speed float32, lifetime float32) {
+	// the bounding box reaches the far corner of a scale by scale square
+	var radius = scale * math.Sqrt2
+	*b = Bullet{Position: position, PrevPosition: position, Scale: scale, Rotation: rotation, Speed: speed, Lifetime: lifetime, Radius: radius}
+}
+
+func NewBullet(position Vector2, scale float32, rotation float32, speed float32, lifetime float32) *Bullet {
+	var b = &Bullet{}
+	b.Init(position, scale, rotation, speed, lifetime)
+	return b
+}
@@ -0,0 +1,447 @@
+package sim
+
+import "math"
+
+// Contact describes how two shapes overlap. Normal points from the first
+// shape towards the second, and moving the second by Normal*Depth separates
+// them.
+type Contact struct {
+	Point  Vector2
+	Normal Vector2
+	Depth  float32
+}
+
+// CollideConvex tests two convex polygons with the separating axis theorem.
+// Either winding works, and one shape lying wholly inside the other counts as
+// a hit.
+func CollideConvex(a []Vector2, b []Vector2) (Contact, bool) {
+	var contact = Contact{Depth: float32(math.Inf(1))}
+	if len(a) == 0 || len(b) == 0 {
+		return contact, false
+	}
+
+	for _, shape := range [2][]Vector2{a, b} {
+		for i := range shape {
+			var edge = Vector2Subtract(shape[(i+1)%len(shape)], shape[i])
+			if edge.X == 0 && edge.Y == 0 {
+				continue
+			}
+			var axis = Vector2Normalize(NewVector2(-edge.Y, edge.X))
+
+			var minA, maxA = project(a, axis)
+			var minB, maxB = project(b, axis)
+			var overlap = min(maxA, maxB) - max(minA, minB)
+			if overlap <= 0 {
+				return Contact{}, false
+			}
+			// a shape inside the other has to be pushed out the shorter way
+			if (minB >= minA && maxB <= maxA) || (minA >= minB && maxA <= maxB) {
+				overlap += min(float32(math.Abs(float64(minA-minB))), float32(math.Abs(float64(maxA-maxB))))
+			}
+
+			if overlap < contact.Depth {
+				contact.Depth = overlap
+				contact.Normal = axis
+			}
+		}
+	}
+
+	if Vector2DotProduct(Vector2Subtract(Centroid(b), Centroid(a)), contact.Normal) < 0 {
+		contact.Normal = Vector2Scale(contact.Normal, -1)
+	}
+
+	// halfway between the deepest points of each shape along the normal
+	var deepestA = support(a, contact.Normal)
+	var deepestB = support(b, Vector2Scale(contact.Normal, -1))
+	contact.Point = Vector2Lerp(deepestA, deepestB, 0.5)
+	return contact, true
+}
+
+// Sweep is where a moving point first touches a shape. T is how far along
+// the path that happened, from 0 at the start to 1 at the end, and Normal
+// faces out of the surface that was hit.
+type Sweep struct {
+	T      float32
+	Point  Vector2
+	Normal Vector2
+}
+
+// SweepConvex finds where the segment from start to end first enters a convex
+// polygon. A segment starting inside hits at T 0, facing back along the path.
+func SweepConvex(start Vector2, end Vector2, points []Vector2) (Sweep, bool) {
+	var area float32 = 0
+	for i := range points {
+		area += cross(points[i], points[(i+1)%len(points)])
+	}
+	if area == 0 {
+		return Sweep{}, false
+	}
+
+	var direction = Vector2Subtract(end, start)
+	var enter float32 = 0
+	var exit float32 = 1
+	var normal = Vector2Normalize(Vector2Scale(direction, -1))
+	for i := range points {
+		var edge = Vector2Subtract(points[(i+1)%len(points)], points[i])
+		var outward = NewVector2(edge.Y, -edge.X)
+		if area < 0 {
+			outward = Vector2Scale(outward, -1)
+		}
+
+		var distance = Vector2DotProduct(outward, Vector2Subtract(points[i], start))
+		var approach = Vector2DotProduct(outward, direction)
+		if approach == 0 {
+			if distance < 0 {
+				return Sweep{}, false
+			}
+			continue
+		}
+
+		var t = distance / approach
+		if approach < 0 {
+			if t > enter {
+				enter = t
+				normal = Vector2Normalize(outward)
+			}
+		} else {
+			exit = min(exit, t)
+		}
+		if enter > exit {
+			return Sweep{}, false
+		}
+	}
+
+	return Sweep{T: enter, Point: Vector2Add(start, Vector2Scale(direction, enter)), Normal: normal}, true
+}
+
+// SweepPieces is SweepConvex against each convex piece, keeping the earliest.
+func SweepPieces(start Vector2, end Vector2, pieces [][]Vector2) (Sweep, bool) {
+	var earliest Sweep
+	var hit = false
+	for _, piece := range pieces {
+		if sweep, ok := SweepConvex(start, end, piece); ok && (!hit || sweep.T < earliest.T) {
+			earliest = sweep
+			hit = true
+		}
+	}
+	return earliest, hit
+}
+
+// CollidePieces tests every convex piece of a against every convex piece of b
+// and returns the deepest contact.
+func CollidePieces(a [][]Vector2, b [][]Vector2) (Contact, bool) {
+	var deepest Contact
+	var hit = false
+	for _, pieceA := range a {
+		for _, pieceB := range b {
+			if contact, ok := CollideConvex(pieceA, pieceB); ok && (!hit || contact.Depth > deepest.Depth) {
+				deepest = contact
+				hit = true
+			}
+		}
+	}
+	return deepest, hit
+}
+
+// collideWrapped tests pieces of a shape at position within radius of an edge,
+// and its copies across the edges, against other pieces within otherRadius.
+// The copies are built in wrapped so the slices get reused.
+func collideWrapped(pieces [][]Vector2, position Vector2, radius float32, other [][]Vector2, otherRadius float32, wrapped *[][]Vector2) (Contact, bool) {
+	if contact, hit := CollidePieces(pieces, other); hit {
+		return contact, true
+	}
+	var offsets, count = WrapOffsets(position, radius+otherRadius)
+	for _, offset := range offsets[1:count] {
+		*wrapped = TranslatePieces(*wrapped, pieces, offset)
+		if contact, hit := CollidePieces(*wrapped, other); hit {
+			return contact, true
+		}
+	}
+	return Contact{}, false
+}
+
+// sweepWrapped finds where the segment from start to end first hits pieces of
+// a shape at position, or one of its copies across the screen edges.
+func sweepWrapped(start Vector2, end Vector2, pieces [][]Vector2, position Vector2, radius float32, wrapped *[][]Vector2) (Sweep, bool) {
+	var earliest, hit = SweepPieces(start, end, pieces)
+	var reach = Vector2Length(Vector2Subtract(end, start))
+	var offsets, count = WrapOffsets(position, radius+reach)
+	for _, offset := range offsets[1:count] {
+		*wrapped = TranslatePieces(*wrapped, pieces, offset)
+		if sweep, ok := SweepPieces(start, end, *wrapped); ok && (!hit || sweep.T < earliest.T) {
+			earliest = sweep
+			hit = true
+		}
+	}
+	return earliest, hit
+}
+
+// CollidePolygons tests two polygons that need not be convex. Shapes tested
+// every step should be decomposed once up front and use CollidePieces.
+func CollidePolygons(a []Vector2, b []Vector2) (Contact, bool) {
+	return CollidePieces(Decompose(a), Decompose(b))
+}
+
+// Decompose splits a polygon into convex pieces. A convex polygon comes back
+// whole, a simple concave one is cut into triangles which are then joined
+// back up where they stay convex, and one whose edges cross is fanned out
+// from its centre so it covers the area it is drawn with.
+func Decompose(points []Vector2) [][]Vector2 {
+	return DecomposeInto(nil, points)
+}
+
+// DecomposeInto is Decompose reusing the slices in dst where there is room.
+func DecomposeInto(dst [][]Vector2, points []Vector2) [][]Vector2 {
+	if len(points) < 3 || IsConvex(points) {
+		dst = resizePieces(dst, 1)
+		dst[0] = append(dst[0], points...)
+		return dst
+	}
+	if IsSimple(points) {
+		if triangles, ok := triangulate(dst, points); ok {
+			return mergeConvex(triangles)
+		}
+	}
+
+	var centre = Centroid(points)
+	dst = resizePieces(dst, len(points))
+	for i := range points {
+		dst[i] = append(dst[i], centre, points[i], points[(i+1)%len(points)])
+	}
+	return dst
+}
+
+// IsConvex reports whether every corner turns the same way. Polygons whose
+// edges cross can still turn one way, so those are checked for too.
+func IsConvex(points []Vector2) bool {
+	var sign float32 = 0
+	for i := range points {
+		var turn = cross(
+			Vector2Subtract(points[(i+1)%len(points)], points[i]),
+			Vector2Subtract(points[(i+2)%len(points)], points[(i+1)%len(points)]),
+		)
+		if turn == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = turn
+		} else if (turn > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return IsSimple(points)
+}
+
+// IsSimple reports whether no two edges that aren't neighbours cross.
+func IsSimple(points []Vector2) bool {
+	var n = len(points)
+	for i := range n {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			var collision = CheckCollisionLines(points[i], points[(i+1)%n], points[j], points[(j+1)%n], nil)
+			if collision {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Centroid is the average of the points, which is inside any convex shape.
+func Centroid(points []Vector2) Vector2 {
+	var sum = Vector2Zero()
+	for _, point := range points {
+		sum = Vector2Add(sum, point)
+	}
+	return Vector2Scale(sum, 1/float32(max(1, len(points))))
+}
+
+// TransformPieces moves local pieces into the world, reusing the slices in
+// dst where there is room.
+func TransformPieces(dst [][]Vector2, pieces [][]Vector2, position Vector2, rotation float32, scale float32) [][]Vector2 {
+	dst = resizePieces(dst, len(pieces))
+	for i, piece := range pieces {
+		for _, point := range piece {
+			dst[i] = append(dst[i], Vector2Add(Vector2Scale(Vector2Rotate(point, DegToRad(rotation)), scale), position))
+		}
+	}
+	return dst
+}
+
+// TranslatePieces copies pieces moved by offset into dst.
+func TranslatePieces(dst [][]Vector2, pieces [][]Vector2, offset Vector2) [][]Vector2 {
+	dst = resizePieces(dst, len(pieces))
+	for i, piece := range pieces {
+		for _, point := range piece {
+			dst[i] = append(dst[i], Vector2Add(point, offset))
+		}
+	}
+	return dst
+}
+
+// makePieces returns no pieces, but with room for count pieces of size points
+// each so filling them in doesn't allocate.
+func makePieces(count int, size int) [][]Vector2 {
+	var pieces = make([][]Vector2, count)
+	for i := range pieces {
+		pieces[i] = make([]Vector2, 0, size)
+	}
+	return pieces[:0]
+}
+
+// appendPiece adds a piece made of points, reusing the slice past the end of
+// pieces if there is one.
+func appendPiece(pieces [][]Vector2, points ...Vector2) [][]Vector2 {
+	if len(pieces) < cap(pieces) {
+		pieces = pieces[:len(pieces)+1]
+	} else {
+		pieces = append(pieces, nil)
+	}
+	var last = len(pieces) - 1
+	pieces[last] = append(pieces[last][:0], points...)
+	return pieces
+}
+
+func resizePieces(pieces [][]Vector2, count int) [][]Vector2 {
+	// slices past the end are kept for reuse rather than replaced
+	if count > cap(pieces) {
+		pieces = append(pieces[:cap(pieces)], make([][]Vector2, count-cap(pieces))...)
+	}
+	pieces = pieces[:count]
+	for i := range pieces {
+		pieces[i] = pieces[i][:0]
+	}
+	return pieces
+}
+
+// triangulate clips ears off a simple polygon until one triangle is left.
+func triangulate(dst [][]Vector2, points []Vector2) ([][]Vector2, bool) {
+	var area float32 = 0
+	for i := range points {
+		area += cross(points[i], points[(i+1)%len(points)])
+	}
+	if area == 0 {
+		return dst, false
+	}
+
+	var buffer [16]int
+	var remaining = buffer[:0]
+	for i := range points {
+		remaining = append(remaining, i)
+	}
+
+	var triangles = resizePieces(dst, 0)
+	for len(remaining) > 3 {
+		var clipped = false
+		for i := range remaining {
+			var prev = points[remaining[(i+len(remaining)-1)%len(remaining)]]
+			var current = points[remaining[i]]
+			var next = points[remaining[(i+1)%len(remaining)]]
+			// corners that turn against the winding are dents, not ears
+			if cross(Vector2Subtract(current, prev), Vector2Subtract(next, current))*area <= 0 {
+				continue
+			}
+			if anyInsideTriangle(points, remaining, i, prev, current, next) {
+				continue
+			}
+
+			triangles = appendPiece(triangles, prev, current, next)
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return triangles, false
+		}
+	}
+
+	return appendPiece(triangles, points[remaining[0]], points[remaining[1]], points[remaining[2]]), true
+}
+
+// mergeConvex joins pieces that share an edge wherever the result is still
+// convex, so fewer pieces need testing.
+func mergeConvex(pieces [][]Vector2) [][]Vector2 {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(pieces) && !merged; i++ {
+			for j := i + 1; j < len(pieces) && !merged; j++ {
+				var buffer [32]Vector2
+				if joined, ok := joinPieces(buffer[:0], pieces[i], pieces[j]); ok && IsConvex(joined) {
+					pieces[i] = append(pieces[i][:0], joined...)
+					// the removed piece's slice moves past the end to be reused
+					var removed = pieces[j]
+					copy(pieces[j:], pieces[j+1:])
+					pieces[len(pieces)-1] = removed[:0]
+					pieces = pieces[:len(pieces)-1]
+					merged = true
+				}
+			}
+		}
+	}
+	return pieces
+}
+
+// joinPieces glues b onto a along an edge they share, which runs opposite
+// ways round the two pieces when they are wound the same way.
+func joinPieces(joined []Vector2, a []Vector2, b []Vector2) ([]Vector2, bool) {
+	for i := range a {
+		for j := range b {
+			if a[i] != b[(j+1)%len(b)] || a[(i+1)%len(a)] != b[j] {
+				continue
+			}
+			joined = append(joined, a[:i+1]...)
+			for k := 2; k < len(b); k++ {
+				joined = append(joined, b[(j+k)%len(b)])
+			}
+			joined = append(joined, a[i+1:]...)
+			return joined, true
+		}
+	}
+	return joined, false
+}
+
+func anyInsideTriangle(points []Vector2, remaining []int, ear int, a Vector2, b Vector2, c Vector2) bool {
+	for j, index := range remaining {
+		if j == ear || j == (ear+1)%len(remaining) || j == (ear+len(remaining)-1)%len(remaining) {
+			continue
+		}
+		var p = points[index]
+		var d1 = cross(Vector2Subtract(b, a), Vector2Subtract(p, a))
+		var d2 = cross(Vector2Subtract(c, b), Vector2Subtract(p, b))
+		var d3 = cross(Vector2Subtract(a, c), Vector2Subtract(p, c))
+		if (d1 > 0 && d2 > 0 && d3 > 0) || (d1 < 0 && d2 < 0 && d3 < 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func project(points []Vector2, axis Vector2) (float32, float32) {
+	var low = float32(math.Inf(1))
+	var high = float32(math.Inf(-1))
+	for _, point := range points {
+		var p = Vector2DotProduct(point, axis)
+		low = min(low, p)
+		high = max(high, p)
+	}
+	return low, high
+}
+
+// support is the point furthest along direction.
+func support(points []Vector2, direction Vector2) Vector2 {
+	var best = points[0]
+	var bestDistance = Vector2DotProduct(best, direction)
+	for _, point := range points[1:] {
+		if distance := Vector2DotProduct(point, direction); distance > bestDistance {
+			best = point
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+func cross(v1 Vector2, v2 Vector2) float32 {
+	return v1.X*v2.Y - v1.Y*v2.X
+}
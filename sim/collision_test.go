@@ -0,0 +1,226 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func square(x, y, size float32) []Vector2 {
+	return []Vector2{
+		NewVector2(x, y),
+		NewVector2(x+size, y),
+		NewVector2(x+size, y+size),
+		NewVector2(x, y+size),
+	}
+}
+
+func reversed(points []Vector2) []Vector2 {
+	var out = make([]Vector2, len(points))
+	for i, point := range points {
+		out[len(points)-1-i] = point
+	}
+	return out
+}
+
+// notched is a 30x30 square with a 10 wide slot cut down into it from the top
+// centre, leaving the region 10<x<20, 10<y<30 empty.
+var notched = []Vector2{
+	NewVector2(0, 0),
+	NewVector2(30, 0),
+	NewVector2(30, 30),
+	NewVector2(20, 30),
+	NewVector2(20, 10),
+	NewVector2(10, 10),
+	NewVector2(10, 30),
+	NewVector2(0, 30),
+}
+
+// bowTie crosses over itself at (5, 5).
+var bowTie = []Vector2{
+	NewVector2(0, 0),
+	NewVector2(10, 10),
+	NewVector2(10, 0),
+	NewVector2(0, 10),
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestCollideConvex(t *testing.T) {
+	var tests = []struct {
+		name   string
+		a, b   []Vector2
+		hit    bool
+		normal Vector2
+		depth  float32
+	}{
+		{name: "apart", a: square(0, 0, 10), b: square(20, 0, 10)},
+		{name: "touching edges", a: square(0, 0, 10), b: square(10, 0, 10)},
+		{name: "overlap right", a: square(0, 0, 10), b: square(8, 1, 10), hit: true, normal: NewVector2(1, 0), depth: 2},
+		{name: "overlap below", a: square(0, 0, 10), b: square(1, 7, 10), hit: true, normal: NewVector2(0, 1), depth: 3},
+		{name: "overlap left", a: square(8, 1, 10), b: square(0, 0, 10), hit: true, normal: NewVector2(-1, 0), depth: 2},
+		{name: "b inside a", a: square(0, 0, 30), b: square(2, 10, 4), hit: true, normal: NewVector2(-1, 0), depth: 6},
+		{name: "a inside b", a: square(2, 10, 4), b: square(0, 0, 30), hit: true, normal: NewVector2(1, 0), depth: 6},
+		{name: "opposite winding", a: reversed(square(0, 0, 10)), b: square(8, 1, 10), hit: true, normal: NewVector2(1, 0), depth: 2},
+		{
+			name: "triangle gap",
+			a:    []Vector2{NewVector2(0, 0), NewVector2(10, 0), NewVector2(0, 10)},
+			b:    square(6, 6, 4),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var contact, hit = CollideConvex(test.a, test.b)
+			if hit != test.hit {
+				t.Fatalf("hit = %v, want %v", hit, test.hit)
+			}
+			if !hit {
+				return
+			}
+			if !near(contact.Normal.X, test.normal.X) || !near(contact.Normal.Y, test.normal.Y) {
+				t.Errorf("normal = %v, want %v", contact.Normal, test.normal)
+			}
+			if !near(contact.Depth, test.depth) {
+				t.Errorf("depth = %v, want %v", contact.Depth, test.depth)
+			}
+
+			// pushing b out along the normal must separate the shapes
+			var moved = TranslatePieces(nil, [][]Vector2{test.b}, Vector2Scale(contact.Normal, contact.Depth+0.01))
+			if _, still := CollideConvex(test.a, moved[0]); still {
+				t.Errorf("still colliding after moving b by %v", contact.Depth)
+			}
+		})
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	var tests = []struct {
+		name   string
+		points []Vector2
+		pieces int
+	}{
+		{name: "convex", points: square(0, 0, 10), pieces: 1},
+		{name: "concave", points: notched},
+		{name: "concave reversed", points: reversed(notched)},
+		{name: "ship", points: ShipOutline},
+		{name: "self intersecting", points: bowTie},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pieces = Decompose(test.points)
+			if test.pieces != 0 && len(pieces) != test.pieces {
+				t.Errorf("got %d pieces, want %d", len(pieces), test.pieces)
+			}
+			for _, piece := range pieces {
+				if len(piece) >= 3 && !IsConvex(piece) {
+					t.Errorf("piece %v is not convex", piece)
+				}
+			}
+		})
+	}
+}
+
+func TestCollidePolygons(t *testing.T) {
+	var tests = []struct {
+		name string
+		a, b []Vector2
+		hit  bool
+	}{
+		{name: "in the notch", a: notched, b: square(12, 15, 6)},
+		{name: "across the notch", a: notched, b: square(5, 15, 10), hit: true},
+		{name: "inside the solid part", a: notched, b: square(2, 2, 4), hit: true},
+		{name: "contains concave shape", a: square(-10, -10, 50), b: notched, hit: true},
+		{name: "inside bow tie", a: bowTie, b: square(1, 4, 2), hit: true},
+		{name: "outside bow tie", a: bowTie, b: square(4, -4, 2)},
+		{
+			// the old edge test only checked whether b's first vertex was
+			// inside a, so a small shape wholly inside was missed
+			name: "bullet inside asteroid",
+			a: []Vector2{
+				NewVector2(-20, -5), NewVector2(0, -20), NewVector2(20, -5),
+				NewVector2(10, 20), NewVector2(-10, 20),
+			},
+			b:   square(-1, -1, 2),
+			hit: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, hit := CollidePolygons(test.a, test.b); hit != test.hit {
+				t.Errorf("CollidePolygons = %v, want %v", hit, test.hit)
+			}
+			if _, hit := CollidePolygons(test.b, test.a); hit != test.hit {
+				t.Errorf("CollidePolygons swapped = %v, want %v", hit, test.hit)
+			}
+			if hit := CheckCollisionPoly(test.a, test.b); hit != test.hit {
+				t.Errorf("CheckCollisionPoly = %v, want %v", hit, test.hit)
+			}
+		})
+	}
+}
+
+func TestSweepConvex(t *testing.T) {
+	var tests = []struct {
+		name       string
+		start, end Vector2
+		hit        bool
+		at         float32
+		normal     Vector2
+	}{
+		{name: "miss", start: NewVector2(-10, -5), end: NewVector2(20, -5)},
+		{name: "stops short", start: NewVector2(-10, 5), end: NewVector2(-1, 5)},
+		{name: "tunnels through", start: NewVector2(-10, 5), end: NewVector2(20, 5), hit: true, at: 1.0 / 3, normal: NewVector2(-1, 0)},
+		{name: "from below", start: NewVector2(5, 30), end: NewVector2(5, 0), hit: true, at: 2.0 / 3, normal: NewVector2(0, 1)},
+		{name: "starts inside", start: NewVector2(5, 5), end: NewVector2(15, 5), hit: true, at: 0, normal: NewVector2(-1, 0)},
+		{name: "ends inside", start: NewVector2(5, -10), end: NewVector2(5, 5), hit: true, at: 2.0 / 3, normal: NewVector2(0, -1)},
+		{name: "parallel along edge outside", start: NewVector2(-5, 11), end: NewVector2(15, 11)},
+	}
+
+	for _, test := range tests {
+		for _, points := range [][]Vector2{square(0, 0, 10), reversed(square(0, 0, 10))} {
+			t.Run(test.name, func(t *testing.T) {
+				var sweep, hit = SweepConvex(test.start, test.end, points)
+				if hit != test.hit {
+					t.Fatalf("hit = %v, want %v", hit, test.hit)
+				}
+				if !hit {
+					return
+				}
+				if !near(sweep.T, test.at) {
+					t.Errorf("T = %v, want %v", sweep.T, test.at)
+				}
+				if !near(sweep.Normal.X, test.normal.X) || !near(sweep.Normal.Y, test.normal.Y) {
+					t.Errorf("normal = %v, want %v", sweep.Normal, test.normal)
+				}
+			})
+		}
+	}
+}
+
+func TestBulletHitsOnlyFirstAsteroid(t *testing.T) {
+	var g = NewGame(1, Options{})
+	// the second asteroid is added first so the earliest hit has to win, not
+	// the first one found
+	g.Asteroids.Clear()
+	var second, _ = g.Asteroids.Add()
+	second.Init(g.Rng, NewVector2(140, 200), 0, Small, 0)
+	var first, _ = g.Asteroids.Add()
+	first.Init(g.Rng, NewVector2(100, 200), 0, Small, 0)
+
+	var bullet, _ = g.Bullets.Add()
+	bullet.Init(NewVector2(180, 200), 10, 0, 480, 1)
+	bullet.PrevPosition = NewVector2(60, 200)
+
+	g.ProcessCollision()
+
+	if !first.ShouldDelete || second.ShouldDelete {
+		t.Errorf("first destroyed = %v, second destroyed = %v, want only first", first.ShouldDelete, second.ShouldDelete)
+	}
+	if !bullet.ShouldDelete {
+		t.Errorf("bullet not used up by the hit")
+	}
+}
@@ -0,0 +1,610 @@
+package sim
+
+import "math"
+
+const WorldWidth float32 = 800
+const WorldHeight float32 = 450
+
+const worldCenterX = WorldWidth / 2
+const worldCenterY = WorldHeight / 2
+
+// TickRate is the number of fixed simulation steps per second. All speeds,
+// rotation rates and drag are expressed per second and scaled by TickDuration.
+const TickRate = 120
+const TickDuration float32 = 1.0 / TickRate
+
+const StartingLives = 3
+
+// ExtraLifeScore is how many points it takes to earn each extra life.
+const ExtraLifeScore = 10000
+
+// RespawnDelay and WaveDelay are in seconds.
+const RespawnDelay float32 = 2
+const WaveDelay float32 = 2
+
+// After RespawnDelay the ship waits for the centre to be clear of asteroids
+// within SafeSpawnRadius, giving up after RespawnTimeout more seconds.
+const SafeSpawnRadius float32 = 80
+const RespawnTimeout float32 = 5
+
+// InvulnerableTime is how long a freshly spawned ship ignores asteroid hits.
+const InvulnerableTime float32 = 3
+
+// MinAsteroidSpawnDistance keeps new asteroids away from the ship.
+const MinAsteroidSpawnDistance float32 = 150
+
+// maxBullets and maxAsteroids are how many the pools hold before they have
+// to grow. A full wave of large asteroids all split down to small ones is 24
+// + 96 + 192 asteroids, though never all alive at once.
+const maxBullets = 128
+const maxAsteroids = 256
+
+// Input is the player's intent for a single step. Fire, Hyperspace, Pause and
+// Restart are edge triggered: the front end sets them only on the step the key
+// went down.
+type Input struct {
+	Thrust      bool
+	RotateLeft  bool
+	RotateRight bool
+	// Turn is analog rotation from -127 (full left) to 127 (full right), added
+	// to the digital rotate buttons. It is an integer so replays stay exact.
+	Turn       int8
+	Fire       bool
+	Hyperspace bool
+	Pause      bool
+	Restart    bool
+}
+
+// Rotation combines the digital and analog rotation inputs into a single
+// amount from -1 (full left) to 1 (full right).
+func (i Input) Rotation() float32 {
+	var rotation = float32(i.Turn) / 127
+	if i.RotateLeft {
+		rotation -= 1
+	}
+	if i.RotateRight {
+		rotation += 1
+	}
+	return max(-1, min(1, rotation))
+}
+
+type EventType int32
+
+const (
+	ShotFired EventType = iota
+	AsteroidDestroyed
+	ShipDied
+	WaveCleared
+	ExtraLife
+	HyperspaceEntered
+	HyperspaceExited
+	SaucerDestroyed
+	LevelCompleted
+)
+
+type Event struct {
+	Type     EventType
+	Position Vector2
+	Size     AsteroidSize
+	// Velocity, Rotation, Scale and Outline describe what fired or was
+	// destroyed, so the front end can draw it flashing or breaking apart.
+	// Rotation is the angle Outline is drawn at, or the direction of a shot.
+	Velocity Vector2
+	Rotation float32
+	Scale    float32
+	Outline  []Vector2
+}
+
+// Options are the rule choices a game starts with. Replays record them since
+// they change how a game plays out.
+type Options struct {
+	// AsteroidPhysics makes asteroids bounce off each other, and shot
+	// asteroids split along the shot instead of in random directions
+	AsteroidPhysics bool
+	// HyperspaceRisk is the percentage chance of the ship breaking up as it
+	// comes out of hyperspace
+	HyperspaceRisk uint8
+	// Level is what is played, ClassicLevel when nil
+	Level *Level
+}
+
+type Game struct {
+	Rng     *Rng
+	Options Options
+	Level   *Level
+
+	// Player is nil while the ship is waiting to respawn or in hyperspace.
+	// RespawnTimer counts down the respawn delay and keeps going negative
+	// while the ship waits for the centre to clear.
+	Player       *PlayerShip
+	RespawnTimer float32
+	// HyperspaceTimer counts down the time left in hyperspace, and is 0 when
+	// the ship isn't in it
+	HyperspaceTimer float32
+
+	Bullets   Pool[Bullet]
+	Asteroids Pool[Asteroid]
+	// Saucer is nil while no saucer is flying. SaucerTimer counts down to the
+	// next one turning up.
+	Saucer      *Saucer
+	SaucerTimer float32
+
+	Score         int32
+	Lives         int32
+	NextExtraLife int32
+	Wave          int32
+	// WaveTimer counts down between clearing a wave and the next one spawning
+	WaveTimer float32
+	// waveShots is how many hits it takes to clear the wave from the start
+	waveShots int32
+
+	// GameOver is set once the ship runs out of lives, or with Won once the
+	// level is complete
+	GameOver bool
+	Won      bool
+	Paused   bool
+
+	events     []Event
+	broadphase *SpatialHash
+	// ship is reused for every respawn, with Player pointing at it while the
+	// ship is alive, and saucer likewise for every saucer
+	ship   PlayerShip
+	saucer Saucer
+}
+
+func NewGame(seed uint64, options Options) *Game {
+	var g = &Game{Rng: NewRng(seed), Options: options, Level: options.Level, broadphase: NewSpatialHash(BroadphaseCellSize)}
+	if g.Level == nil {
+		g.Level = &ClassicLevel
+	}
+	g.Bullets.Reserve(maxBullets, nil)
+	g.Asteroids.Reserve(maxAsteroids, (*Asteroid).AllocateBuffers)
+	g.ship.AllocateBuffers()
+	g.saucer.AllocateBuffers()
+	g.events = make([]Event, 0, 64)
+	g.Restart()
+	return g
+}
+
+func (g *Game) Restart() {
+	g.GameOver = false
+	g.Won = false
+	g.Paused = false
+	g.Bullets.Clear()
+	g.Asteroids.Clear()
+	g.Saucer = nil
+
+	g.Score = 0
+	g.Lives = StartingLives
+	g.NextExtraLife = ExtraLifeScore
+	g.Wave = 0
+	g.WaveTimer = 0
+	g.HyperspaceTimer = 0
+
+	g.SpawnPlayer()
+	g.StartNextWave()
+	g.SaucerTimer = g.SaucerInterval()
+}
+
+func (g *Game) SpawnPlayer() {
+	g.ship.Init(NewVector2(worldCenterX, worldCenterY), 0, 20.0, 120)
+	g.Player = &g.ship
+	g.Player.Invulnerable = InvulnerableTime
+	g.RespawnTimer = 0
+}
+
+func (g *Game) IsSpawnAreaClear() bool {
+	return g.IsAreaClear(NewVector2(worldCenterX, worldCenterY))
+}
+
+// IsAreaClear reports whether no asteroid is within SafeSpawnRadius of center,
+// counting asteroids across the screen edges.
+func (g *Game) IsAreaClear(center Vector2) bool {
+	var offsets, count = WrapOffsets(center, SafeSpawnRadius)
+	for _, a := range g.Asteroids.Items() {
+		var points = a.GetScaledRenderPoints()
+		for _, offset := range offsets[:count] {
+			if CheckCollisionCirclePoly(Vector2Add(center, offset), SafeSpawnRadius, points) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AsteroidSpawnPosition picks a random point in region that is at least
+// MinAsteroidSpawnDistance from the ship, or from where it will respawn.
+func (g *Game) AsteroidSpawnPosition(region Rectangle) Vector2 {
+	var ship = NewVector2(worldCenterX, worldCenterY)
+	if g.Player != nil {
+		ship = g.Player.Position
+	}
+
+	var position Vector2
+	for range 20 {
+		var x = g.Rng.GetRandomValue(int32(region.X), int32(region.X+region.Width))
+		var y = g.Rng.GetRandomValue(int32(region.Y), int32(region.Y+region.Height))
+		position = NewVector2(float32(x), float32(y))
+		if WrappedDistance(position, ship) >= MinAsteroidSpawnDistance {
+			return position
+		}
+	}
+
+	// the region is mostly covered by the ship's safe zone, so push the last
+	// try straight out of it
+	var away = Vector2Normalize(Vector2Subtract(position, ship))
+	if away == Vector2Zero() {
+		away = NewVector2(1, 0)
+	}
+	return WrapCoordinates(Vector2Add(ship, Vector2Scale(away, MinAsteroidSpawnDistance)))
+}
+
+// StartNextWave spawns the asteroids of the level's next wave.
+func (g *Game) StartNextWave() {
+	g.Wave++
+	var wave = g.CurrentWave()
+
+	g.waveShots = 0
+	for _, group := range [...]struct {
+		size  AsteroidSize
+		count int32
+	}{{Large, wave.Large}, {Medium, wave.Medium}, {Small, wave.Small}} {
+		for range group.count {
+			g.spawnWaveAsteroid(wave, group.size)
+		}
+	}
+	for range wave.Random {
+		g.spawnWaveAsteroid(wave, AsteroidSize(g.Rng.GetRandomValue(int32(Small), int32(Large))))
+	}
+}
+
+func (g *Game) spawnWaveAsteroid(wave *LevelWave, size AsteroidSize) {
+	var position = g.AsteroidSpawnPosition(wave.Region)
+	var heading = g.Rng.GetRandomValue(0, 360)
+	var speed = wave.Speed.Min + (wave.Speed.Max-wave.Speed.Min)*g.Rng.GetRandomValueF(0, 100)/100
+	g.SpawnAsteroid(position, float32(heading), speed, size)
+	g.waveShots += g.shotsToClear(size)
+}
+
+// shotsToClear is how many hits it takes to get rid of an asteroid and all
+// the fragments it splits into on this level.
+func (g *Game) shotsToClear(size AsteroidSize) int32 {
+	var splits = g.Level.Splits
+	switch size {
+	case Medium:
+		return 1 + splits.Medium*g.shotsToClear(Small)
+	case Large:
+		return 1 + splits.Large*g.shotsToClear(Medium)
+	}
+	return 1
+}
+
+// WaveProgress is how much of the current wave has been cleared, from 0 as it
+// starts to nearly 1 with the last few small asteroids left. It is 0 between
+// waves.
+func (g *Game) WaveProgress() float32 {
+	if g.WaveTimer > 0 || g.waveShots == 0 {
+		return 0
+	}
+	var remaining int32 = 0
+	for _, a := range g.Asteroids.Items() {
+		remaining += g.shotsToClear(a.Size)
+	}
+	return max(0, 1-float32(remaining)/float32(g.waveShots))
+}
+
+// Step advances the simulation by one TickDuration. The returned events are
+// only valid until the next call to Step.
+func (g *Game) Step(input Input) []Event {
+	g.events = g.events[:0]
+	g.storePreviousState()
+
+	if !g.Paused && !g.GameOver {
+		g.ProcessPlayer(input, TickDuration)
+		g.ProcessSaucer(TickDuration)
+		g.ProcessBullets(TickDuration)
+		g.ProcessAsteroids(TickDuration)
+		g.ProcessCollision()
+		g.ProcessWave(TickDuration)
+	}
+
+	if input.Pause {
+		g.Paused = !g.Paused
+	}
+
+	if input.Restart {
+		g.Restart()
+	}
+
+	return g.events
+}
+
+// storePreviousState remembers where everything was before this step so the
+// front end can interpolate between the last two steps when rendering.
+func (g *Game) storePreviousState() {
+	if g.Player != nil {
+		g.Player.PrevPosition = g.Player.Position
+		g.Player.PrevRotation = g.Player.Rotation
+	}
+	if g.Saucer != nil {
+		g.Saucer.PrevPosition = g.Saucer.Position
+	}
+	for _, b := range g.Bullets.Items() {
+		b.PrevPosition = b.Position
+	}
+	for _, a := range g.Asteroids.Items() {
+		a.PrevPosition = a.Position
+		a.PrevRotation = a.Rotation
+	}
+}
+
+func (g *Game) emit(event Event) {
+	g.events = append(g.events, event)
+}
+
+// ProcessCollision puts the asteroids in the broadphase grid, then only runs
+// the polygon tests for the ones whose bounding circles touch. Fragments
+// split off this step are left out until the next one.
+func (g *Game) ProcessCollision() {
+	var asteroids = g.Asteroids.Items()
+	g.broadphase.Clear()
+	for i, a := range asteroids {
+		a.UpdateCollisionPieces()
+		g.broadphase.Insert(i, a.Position, a.Radius)
+	}
+	if g.Player != nil {
+		g.Player.UpdateCollisionPieces()
+	}
+	if g.Saucer != nil {
+		g.Saucer.UpdateCollisionPieces()
+	}
+
+	g.ProcessBulletCollisions(asteroids)
+	g.ProcessPlayerCollision(asteroids)
+	g.ProcessSaucerCollision(asteroids)
+	if g.Options.AsteroidPhysics {
+		g.ProcessAsteroidCollisions(asteroids)
+	}
+}
+
+// ProcessBulletCollisions has each bullet sweep the path it moved along this
+// step and only hit the first thing on it, so fast bullets can't skip past
+// small asteroids or take out two at once. The ship's bullets can also hit
+// the saucer, and the saucer's the ship.
+func (g *Game) ProcessBulletCollisions(asteroids []*Asteroid) {
+	for _, b := range g.Bullets.Items() {
+		if b.ShouldDelete {
+			continue
+		}
+
+		var centre = Vector2Lerp(b.PrevPosition, b.Position, 0.5)
+		var reach = Vector2Length(Vector2Subtract(b.Position, b.PrevPosition)) / 2
+		var target *Asteroid
+		// later than any hit, so the first one found is always earlier
+		var earliest = Sweep{T: math.MaxFloat32}
+		for _, i := range g.broadphase.Query(centre, reach) {
+			var a = asteroids[i]
+			if a.ShouldDelete || WrappedDistance(a.Position, centre) > a.Radius+reach {
+				continue
+			}
+			if sweep, hit := a.SweepWrapped(b.PrevPosition, b.Position); hit && sweep.T < earliest.T {
+				target = a
+				earliest = sweep
+			}
+		}
+
+		var hitPlayer, hitSaucer = false, false
+		if player := g.Player; b.FromSaucer && player != nil && player.Invulnerable <= 0 {
+			if sweep, hit := player.SweepWrapped(b.PrevPosition, b.Position); hit && sweep.T < earliest.T {
+				hitPlayer = true
+				earliest = sweep
+			}
+		}
+		if saucer := g.Saucer; !b.FromSaucer && saucer != nil {
+			if sweep, hit := saucer.SweepWrapped(b.PrevPosition, b.Position); hit && sweep.T < earliest.T {
+				hitSaucer = true
+				earliest = sweep
+			}
+		}
+
+		switch {
+		case hitPlayer:
+			g.KillPlayer()
+		case hitSaucer:
+			g.AddScore(g.Saucer.Size.Points())
+			g.DestroySaucer()
+		case target != nil:
+			var direction = Vector2Rotate(NewVector2(1, 0), DegToRad(b.Rotation))
+			g.DestroyAsteroid(target, Vector2Scale(direction, BulletImpulse))
+			if !b.FromSaucer {
+				g.AddScore(target.Size.Points())
+			}
+		default:
+			continue
+		}
+		b.ShouldDelete = true
+	}
+}
+
+func (g *Game) ProcessPlayerCollision(asteroids []*Asteroid) {
+	if g.Player == nil || g.Player.Invulnerable > 0 {
+		return
+	}
+
+	var player = g.Player
+	for _, i := range g.broadphase.Query(player.Position, player.Radius) {
+		var a = asteroids[i]
+		if WrappedDistance(a.Position, player.Position) > a.Radius+player.Radius {
+			continue
+		}
+		if _, hit := a.CollidesWrapped(player.CollisionPieces(), player.Radius); hit {
+			g.KillPlayer()
+			return
+		}
+	}
+}
+
+// DestroyAsteroid splits an asteroid into smaller ones, leaving any scoring to
+// the caller.
+// With asteroid physics on the fragments carry on with the asteroid's
+// momentum plus impulse, otherwise they scatter in random directions.
+func (g *Game) DestroyAsteroid(a *Asteroid, impulse Vector2) {
+	var splits = g.Level.Splits
+	if g.Options.AsteroidPhysics {
+		switch a.Size {
+		case Large:
+			g.splitWithMomentum(a, impulse, int(splits.Large), 5, 12)
+		case Medium:
+			g.splitWithMomentum(a, impulse, int(splits.Medium), 3, 20)
+		}
+	} else if a.Size == Large {
+		g.splitRandomly(a, int(splits.Large), 5, 12)
+	} else if a.Size == Medium {
+		g.splitRandomly(a, int(splits.Medium), 3, 20)
+	}
+	g.emit(Event{
+		Type: AsteroidDestroyed, Position: a.Position, Size: a.Size,
+		Velocity: a.Velocity, Rotation: a.Rotation, Scale: a.Scale, Outline: a.RenderPoints,
+	})
+	a.ShouldDelete = true
+}
+
+// splitRandomly breaks an asteroid into fragments heading off at random angles
+// from its course, each a random number of speedSteps of stepSpeed faster.
+func (g *Game) splitRandomly(a *Asteroid, count int, speedSteps int32, stepSpeed float32) {
+	var heading = a.Heading()
+	var speed = Vector2Length(a.Velocity)
+	for range count {
+		g.SpawnAsteroid(a.Position, heading+g.Rng.GetRandomAngle(), speed+g.Rng.GetRandomValueF(0, speedSteps)*stepSpeed, a.Size-1)
+	}
+}
+
+func (g *Game) KillPlayer() {
+	var player = g.Player
+	g.emit(Event{
+		Type: ShipDied, Position: player.Position,
+		Velocity: player.Velocity, Rotation: player.Rotation - 90, Scale: player.Scale, Outline: player.RenderPoints,
+	})
+	g.Player = nil
+	g.Lives--
+	if g.Lives <= 0 {
+		g.GameOver = true
+		return
+	}
+	g.RespawnTimer = RespawnDelay
+}
+
+func (g *Game) AddScore(points int32) {
+	g.Score += points
+	for g.Score >= g.NextExtraLife {
+		g.Lives++
+		g.NextExtraLife += ExtraLifeScore
+		g.emit(Event{Type: ExtraLife})
+	}
+	g.CheckWin(0)
+}
+
+func (g *Game) ProcessWave(dt float32) {
+	if g.WaveTimer > 0 {
+		g.WaveTimer -= dt
+		if g.WaveTimer <= 0 {
+			g.StartNextWave()
+		}
+		return
+	}
+
+	if g.Asteroids.Len() == 0 {
+		g.emit(Event{Type: WaveCleared})
+		g.CheckWin(g.Wave)
+		if !g.GameOver {
+			g.WaveTimer = WaveDelay
+		}
+	}
+}
+
+func (g *Game) ProcessAsteroids(dt float32) {
+	g.Asteroids.RemoveIf(func(a *Asteroid) bool { return a.ShouldDelete })
+
+	for _, a := range g.Asteroids.Items() {
+		a.Position = WrapCoordinates(Vector2Add(a.Position, Vector2Scale(a.Velocity, dt)))
+		a.Rotation += a.AngularVelocity * dt
+	}
+}
+
+func (g *Game) ProcessBullets(dt float32) {
+	// Cleanup before processing again
+	g.Bullets.RemoveIf(func(b *Bullet) bool { return b.ShouldDelete })
+
+	var bullets = g.Bullets.Items()
+	for i := range bullets {
+		bullets[i].Lifetime -= dt
+
+		if bullets[i].Lifetime <= 0 {
+			bullets[i].ShouldDelete = true
+		}
+		var theta = float64(DegToRad(bullets[i].Rotation))
+		var direction = NewVector2(float32(math.Cos(theta)), float32(math.Sin(theta)))
+		bullets[i].Position = Vector2Add(bullets[i].Position, Vector2Scale(direction, bullets[i].Speed*dt))
+	}
+}
+
+func (g *Game) ProcessPlayer(input Input, dt float32) {
+	if g.Player == nil {
+		if g.HyperspaceTimer > 0 {
+			g.HyperspaceTimer -= dt
+			if g.HyperspaceTimer <= 0 {
+				g.ExitHyperspace()
+			}
+			return
+		}
+
+		g.RespawnTimer -= dt
+		if g.RespawnTimer <= 0 && (g.IsSpawnAreaClear() || g.RespawnTimer <= -RespawnTimeout) {
+			g.SpawnPlayer()
+		}
+		return
+	}
+
+	var player = g.Player
+	player.Invulnerable = max(0, player.Invulnerable-dt)
+	player.HyperspaceCooldown = max(0, player.HyperspaceCooldown-dt)
+	if input.Hyperspace && player.HyperspaceCooldown == 0 {
+		g.EnterHyperspace()
+		return
+	}
+
+	// fraction of velocity lost per second, roughly 1.5% per 60 Hz frame
+	const drag = 0.6
+	const rotationSpeed = 180
+
+	var theta = float64(DegToRad(player.Rotation))
+	var lookDirection = NewVector2(float32(math.Cos(theta)), float32(math.Sin(theta)))
+	player.Thrusting = input.Thrust
+	if input.Thrust {
+		player.Velocity = Vector2Add(player.Velocity, Vector2Scale(lookDirection, player.Speed*dt))
+	}
+
+	player.Rotation += input.Rotation() * rotationSpeed * dt
+
+	if input.Fire {
+		g.emit(Event{Type: ShotFired, Position: player.Position, Velocity: player.Velocity, Rotation: player.Rotation, Scale: player.Scale})
+		g.SpawnBullet(player.Position, player.Rotation, 480)
+	}
+
+	player.Velocity = Vector2Scale(player.Velocity, float32(math.Pow(1-drag, float64(dt))))
+	player.Position = Vector2Add(player.Position, Vector2Scale(player.Velocity, dt))
+
+	g.Player.Position = WrapCoordinates(g.Player.Position)
+}
+
+func (g *Game) SpawnBullet(spawnPosition Vector2, rotation float32, speed float32) *Bullet {
+	var bullet, _ = g.Bullets.Add()
+	bullet.Init(spawnPosition, 10, rotation, speed, 1)
+	return bullet
+}
+
+func (g *Game) SpawnAsteroid(spawnPosition Vector2, heading float32, speed float32, size AsteroidSize) *Asteroid {
+	var asteroid, _ = g.Asteroids.Add()
+	asteroid.Init(g.Rng, spawnPosition, heading, size, speed)
+	return asteroid
+}
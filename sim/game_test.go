@@ -0,0 +1,220 @@
+package sim
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestStepHeadless(t *testing.T) {
+	var g = NewGame(1, Options{})
+	if g.Player == nil || g.Asteroids.Len() == 0 || g.Wave != 1 {
+		t.Fatalf("new game has player %v, %d asteroids and wave %d", g.Player, g.Asteroids.Len(), g.Wave)
+	}
+
+	var start = g.Player.Position
+	var shots = 0
+	for tick := range 2 * TickRate {
+		for _, event := range g.Step(Input{Thrust: true, Fire: tick%30 == 0}) {
+			if event.Type == ShotFired {
+				shots++
+			}
+		}
+	}
+
+	if g.Player != nil && g.Player.Position == start {
+		t.Errorf("thrusting ship never moved from %v", start)
+	}
+	if shots != 2*TickRate/30 {
+		t.Errorf("fired %d shots, want %d", shots, 2*TickRate/30)
+	}
+}
+
+// stateDifference describes the first way two games differ, or is empty when
+// they are in the same state.
+func stateDifference(a *Game, b *Game) string {
+	if a.Score != b.Score || a.Lives != b.Lives || a.Wave != b.Wave || a.GameOver != b.GameOver {
+		return fmt.Sprintf("score, lives, wave and game over %d %d %d %v vs %d %d %d %v",
+			a.Score, a.Lives, a.Wave, a.GameOver, b.Score, b.Lives, b.Wave, b.GameOver)
+	}
+	if (a.Player == nil) != (b.Player == nil) || (a.Player != nil && a.Player.Position != b.Player.Position) {
+		return "player differs"
+	}
+	if a.Asteroids.Len() != b.Asteroids.Len() {
+		return fmt.Sprintf("%d asteroids vs %d", a.Asteroids.Len(), b.Asteroids.Len())
+	}
+	for i, asteroid := range a.Asteroids.Items() {
+		var other = b.Asteroids.Items()[i]
+		if asteroid.Position != other.Position || asteroid.Size != other.Size {
+			return fmt.Sprintf("asteroid %d at %v vs %v", i, asteroid.Position, other.Position)
+		}
+	}
+	var rngA, _ = a.Rng.MarshalBinary()
+	var rngB, _ = b.Rng.MarshalBinary()
+	if !bytes.Equal(rngA, rngB) {
+		return "random number generators are out of step"
+	}
+	return ""
+}
+
+func TestSameSeedSameGame(t *testing.T) {
+	var a = NewGame(42, Options{})
+	var b = NewGame(42, Options{})
+	for tick := range 60 * TickRate {
+		a.Step(scriptedInput(tick))
+		b.Step(scriptedInput(tick))
+		if difference := stateDifference(a, b); difference != "" {
+			t.Fatalf("games split apart at step %d: %s", tick, difference)
+		}
+	}
+
+	var c = NewGame(43, Options{})
+	if stateDifference(a, c) == "" {
+		t.Errorf("a different seed played out the same")
+	}
+}
+
+func TestExtraLives(t *testing.T) {
+	var tests = []struct {
+		name   string
+		points []int32
+		lives  int32
+	}{
+		{"just short", []int32{ExtraLifeScore - 1}, StartingLives},
+		{"exactly one", []int32{ExtraLifeScore}, StartingLives + 1},
+		{"crossed in steps", []int32{ExtraLifeScore / 2, ExtraLifeScore / 2}, StartingLives + 1},
+		{"each threshold", []int32{ExtraLifeScore, ExtraLifeScore, ExtraLifeScore}, StartingLives + 3},
+		{"several at once", []int32{3*ExtraLifeScore + 5}, StartingLives + 3},
+	}
+	for _, test := range tests {
+		var g = NewGame(1, Options{})
+		var extraLives int32 = 0
+		for _, points := range test.points {
+			g.events = g.events[:0]
+			g.AddScore(points)
+			for _, event := range g.events {
+				if event.Type == ExtraLife {
+					extraLives++
+				}
+			}
+		}
+		if g.Lives != test.lives || extraLives != test.lives-StartingLives {
+			t.Errorf("%s: ended with %d lives and %d ExtraLife events, want %d lives", test.name, g.Lives, extraLives, test.lives)
+		}
+	}
+}
+
+func TestGameOver(t *testing.T) {
+	var tests = []struct {
+		lives    int32
+		deaths   int
+		gameOver bool
+	}{
+		{1, 1, true},
+		{3, 2, false},
+		{3, 3, true},
+		{5, 4, false},
+	}
+	for _, test := range tests {
+		var g = NewGame(1, Options{})
+		g.Lives = test.lives
+		for range test.deaths {
+			g.SpawnPlayer()
+			g.KillPlayer()
+		}
+		if g.GameOver != test.gameOver || g.Lives != test.lives-int32(test.deaths) {
+			t.Errorf("%d deaths from %d lives left %d lives and game over = %v, want %v",
+				test.deaths, test.lives, g.Lives, g.GameOver, test.gameOver)
+		}
+		if !g.GameOver && g.RespawnTimer != RespawnDelay {
+			t.Errorf("%d deaths from %d lives set the respawn timer to %v", test.deaths, test.lives, g.RespawnTimer)
+		}
+	}
+}
+
+func TestWaveEscalation(t *testing.T) {
+	var tests = []struct {
+		wave      int32
+		asteroids int
+		speed     float32
+	}{
+		{1, 10, 60},
+		{2, 12, 70},
+		{5, 18, 100},
+		{8, 24, 130},
+		{10, 24, 150},
+		// the last wave repeats
+		{14, 24, 150},
+	}
+	for _, test := range tests {
+		var g = NewGame(1, Options{})
+		g.Asteroids.Clear()
+		g.Wave = test.wave - 1
+		g.StartNextWave()
+		if g.Wave != test.wave || g.Asteroids.Len() != test.asteroids {
+			t.Errorf("wave %d started with %d asteroids, want %d", g.Wave, g.Asteroids.Len(), test.asteroids)
+		}
+		for _, a := range g.Asteroids.Items() {
+			if speed := Vector2Length(a.Velocity); speed < test.speed-0.1 || speed > test.speed+0.1 {
+				t.Errorf("wave %d asteroid moves at %v, want %v", test.wave, speed, test.speed)
+				break
+			}
+		}
+	}
+}
+
+// centerAsteroid leaves a single still large asteroid over the middle of the
+// world, where the ship spawns.
+func centerAsteroid(g *Game) {
+	g.Asteroids.Clear()
+	g.SpawnAsteroid(NewVector2(worldCenterX, worldCenterY), 0, 0, Large)
+}
+
+func TestRespawn(t *testing.T) {
+	var g = NewGame(1, Options{})
+	g.Asteroids.Clear()
+	g.KillPlayer()
+	for range int(RespawnDelay*TickRate) - 1 {
+		g.ProcessPlayer(Input{}, TickDuration)
+	}
+	if g.Player != nil {
+		t.Errorf("ship respawned before the respawn delay")
+	}
+	g.ProcessPlayer(Input{}, TickDuration)
+	g.ProcessPlayer(Input{}, TickDuration)
+	if g.Player == nil {
+		t.Errorf("ship didn't respawn into a clear area after the respawn delay")
+	}
+
+	g = NewGame(1, Options{})
+	centerAsteroid(g)
+	g.KillPlayer()
+	for range int((RespawnDelay+RespawnTimeout)*TickRate) - 1 {
+		g.ProcessPlayer(Input{}, TickDuration)
+		if g.Player != nil {
+			t.Fatalf("ship respawned under an asteroid with the timer at %v", g.RespawnTimer)
+		}
+	}
+	g.ProcessPlayer(Input{}, TickDuration)
+	g.ProcessPlayer(Input{}, TickDuration)
+	if g.Player == nil {
+		t.Errorf("ship still waiting after the respawn timeout")
+	}
+}
+
+func TestInvulnerableShipSurvivesCollisions(t *testing.T) {
+	var g = NewGame(1, Options{})
+	centerAsteroid(g)
+	for range int(InvulnerableTime*TickRate) - 1 {
+		g.Step(Input{})
+		if g.Player == nil {
+			t.Fatalf("invulnerable ship died with %v seconds left", g.ship.Invulnerable)
+		}
+	}
+	for range TickRate {
+		g.Step(Input{})
+	}
+	if g.Player != nil || g.Lives != StartingLives-1 {
+		t.Errorf("ship survived sitting in an asteroid once no longer invulnerable")
+	}
+}
@@ -0,0 +1,56 @@
+package sim
+
+// HyperspaceDelay is how many seconds the ship is gone for when it jumps, and
+// HyperspaceCooldownTime how long after coming back before it can jump again.
+const HyperspaceDelay float32 = 1
+const HyperspaceCooldownTime float32 = 5
+
+// EnterHyperspace takes the ship out of play until HyperspaceDelay is up.
+func (g *Game) EnterHyperspace() {
+	g.emit(Event{Type: HyperspaceEntered, Position: g.Player.Position})
+	g.Player = nil
+	g.HyperspaceTimer = HyperspaceDelay
+}
+
+// ExitHyperspace brings the ship back at a random spot, stopped but facing the
+// same way. Options.HyperspaceRisk is the chance it breaks up on the way out.
+func (g *Game) ExitHyperspace() {
+	var position = g.HyperspaceExitPosition()
+	var ship = &g.ship
+	ship.Position = position
+	ship.PrevPosition = position
+	ship.Velocity = Vector2Zero()
+	ship.HyperspaceCooldown = HyperspaceCooldownTime
+	g.Player = ship
+	g.HyperspaceTimer = 0
+	g.emit(Event{Type: HyperspaceExited, Position: position})
+
+	if g.Rng.GetRandomValue(1, 100) <= int32(g.Options.HyperspaceRisk) {
+		g.KillPlayer()
+	}
+}
+
+// HyperspaceExitPosition picks a random point clear of asteroids the same way
+// a respawn waits for the centre to be clear. If a few tries don't find one
+// the ship takes its chances with the last.
+func (g *Game) HyperspaceExitPosition() Vector2 {
+	var position Vector2
+	for range 20 {
+		var x = g.Rng.GetRandomValue(0, int32(WorldWidth)-1)
+		var y = g.Rng.GetRandomValue(0, int32(WorldHeight)-1)
+		position = NewVector2(float32(x), float32(y))
+		if g.IsAreaClear(position) {
+			return position
+		}
+	}
+	return position
+}
+
+// HyperspaceCharge is how ready the next jump is, from 0 just after one to 1
+// when the ship can jump again. It is 0 while there is no ship.
+func (g *Game) HyperspaceCharge() float32 {
+	if g.Player == nil {
+		return 0
+	}
+	return 1 - g.Player.HyperspaceCooldown/HyperspaceCooldownTime
+}
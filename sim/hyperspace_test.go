@@ -0,0 +1,43 @@
+package sim
+
+import "testing"
+
+func TestHyperspace(t *testing.T) {
+	for _, test := range []struct {
+		risk      uint8
+		survives  bool
+		wantLives int32
+	}{
+		{risk: 0, survives: true, wantLives: StartingLives},
+		{risk: 100, survives: false, wantLives: StartingLives - 1},
+	} {
+		var g = NewGame(1, Options{HyperspaceRisk: test.risk})
+		g.Player.Invulnerable = 0
+		g.Step(Input{Hyperspace: true})
+		if g.Player != nil {
+			t.Fatalf("ship still in play after jumping")
+		}
+
+		var exited = false
+		for range int(HyperspaceDelay*TickRate) + 1 {
+			for _, event := range g.Step(Input{}) {
+				exited = exited || event.Type == HyperspaceExited
+			}
+		}
+		if !exited {
+			t.Fatalf("ship did not come back after %v seconds", HyperspaceDelay)
+		}
+		if (g.Player != nil) != test.survives || g.Lives != test.wantLives {
+			t.Errorf("risk %d%%: alive = %v with %d lives, want %v with %d", test.risk, g.Player != nil, g.Lives, test.survives, test.wantLives)
+		}
+		if test.survives {
+			if !g.IsAreaClear(g.Player.Position) {
+				t.Errorf("ship came back next to an asteroid at %v", g.Player.Position)
+			}
+			g.Step(Input{Hyperspace: true})
+			if g.Player == nil {
+				t.Errorf("ship jumped again during the cooldown")
+			}
+		}
+	}
+}
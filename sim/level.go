@@ -0,0 +1,207 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Limits on what a level can ask for, to catch typos before they spawn
+// thousands of asteroids.
+const maxWaveAsteroids = 64
+const maxSplits = 8
+const maxAsteroidSpeed float32 = 500
+
+// Level describes how a game plays out: the asteroids of each wave, how they
+// split, when saucers turn up and what it takes to win.
+type Level struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Waves are played in order, the last one repeating once they run out
+	Waves  []LevelWave `json:"waves"`
+	Splits Splits      `json:"splits"`
+	// Saucers is nil for a level without any
+	Saucers *SaucerSchedule `json:"saucers,omitempty"`
+	Win     WinCondition    `json:"win"`
+}
+
+// LevelWave is how many asteroids of each size a wave starts with, plus
+// Random more of a random size, and where and how fast they spawn.
+type LevelWave struct {
+	Large  int32 `json:"large"`
+	Medium int32 `json:"medium"`
+	Small  int32 `json:"small"`
+	Random int32 `json:"random"`
+	// Region is where they spawn, keeping MinAsteroidSpawnDistance from the
+	// ship
+	Region Rectangle `json:"region"`
+	// Speed is in units per second
+	Speed Range `json:"speed"`
+}
+
+type Range struct {
+	Min float32 `json:"min"`
+	Max float32 `json:"max"`
+}
+
+// Splits is how many pieces large and medium asteroids break into.
+type Splits struct {
+	Large  int32 `json:"large"`
+	Medium int32 `json:"medium"`
+}
+
+// SaucerSchedule sends the first saucer in First seconds into a game, and
+// each wave brings the next one Decrease seconds sooner, down to Min.
+type SaucerSchedule struct {
+	First    float32 `json:"first"`
+	Min      float32 `json:"min"`
+	Decrease float32 `json:"decrease"`
+}
+
+// WinCondition ends the game in a win once Waves waves are cleared or the
+// score reaches Score. A level with neither goes on until the ship runs out
+// of lives.
+type WinCondition struct {
+	Waves int32 `json:"waves"`
+	Score int32 `json:"score"`
+}
+
+// ClassicLevel is the endless game: a field that grows and speeds up with
+// every wave, starting with the original ten asteroids moving at 60 units per
+// second.
+var ClassicLevel = classicLevel()
+
+func classicLevel() Level {
+	var level = Level{
+		Name:        "Classic",
+		Description: "Endless waves that keep getting bigger and faster",
+		Splits:      Splits{Large: 4, Medium: 2},
+		Saucers:     &SaucerSchedule{First: 20, Min: 6, Decrease: 2},
+	}
+	for wave := range int32(10) {
+		level.Waves = append(level.Waves, LevelWave{
+			Random: min(10+2*wave, 24),
+			Region: NewRectangle(0, 0, WorldWidth/2, WorldHeight/2),
+			Speed:  Range{Min: 60 + 10*float32(wave), Max: 60 + 10*float32(wave)},
+		})
+	}
+	return level
+}
+
+// LoadLevel reads a level from JSON, rejecting unknown fields so a misspelt
+// one doesn't silently fall back to its zero value. Splits and wave speeds
+// left out are taken from the classic level rather than zero, which would
+// make asteroids vanish when shot or sit still.
+func LoadLevel(contents []byte) (*Level, error) {
+	var decoder = json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+
+	var level = Level{Splits: ClassicLevel.Splits}
+	if err := decoder.Decode(&level); err != nil {
+		return nil, err
+	}
+	if err := level.Validate(); err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// Validate reports the first problem with the level, naming where in the file
+// it is.
+func (l *Level) Validate() error {
+	if l.Name == "" {
+		return errors.New("name: missing")
+	}
+	if len(l.Waves) == 0 {
+		return errors.New("waves: a level needs at least one wave")
+	}
+
+	for i, wave := range l.Waves {
+		if err := wave.validate(); err != nil {
+			return fmt.Errorf("waves[%d].%w", i, err)
+		}
+	}
+
+	if l.Splits.Large < 0 || l.Splits.Large > maxSplits {
+		return fmt.Errorf("splits.large: %d is not between 0 and %d", l.Splits.Large, maxSplits)
+	}
+	if l.Splits.Medium < 0 || l.Splits.Medium > maxSplits {
+		return fmt.Errorf("splits.medium: %d is not between 0 and %d", l.Splits.Medium, maxSplits)
+	}
+
+	if s := l.Saucers; s != nil {
+		if s.First <= 0 || s.Min <= 0 {
+			return fmt.Errorf("saucers: first %v and min %v must be above 0", s.First, s.Min)
+		}
+		if s.Decrease < 0 {
+			return fmt.Errorf("saucers.decrease: %v is negative", s.Decrease)
+		}
+	}
+
+	if l.Win.Waves < 0 || l.Win.Score < 0 {
+		return fmt.Errorf("win: waves %d and score %d can't be negative", l.Win.Waves, l.Win.Score)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON fills in the classic first wave's speed when a wave leaves it
+// out.
+func (w *LevelWave) UnmarshalJSON(contents []byte) error {
+	// levelWave drops the method, so decoding it doesn't recurse
+	type levelWave LevelWave
+	var wave = levelWave{Speed: ClassicLevel.Waves[0].Speed}
+	var decoder = json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&wave); err != nil {
+		return err
+	}
+	*w = LevelWave(wave)
+	return nil
+}
+
+func (w *LevelWave) validate() error {
+	if w.Large < 0 || w.Medium < 0 || w.Small < 0 || w.Random < 0 {
+		return errors.New("asteroid counts can't be negative")
+	}
+	if count := w.count(); count == 0 || count > maxWaveAsteroids {
+		return fmt.Errorf("%d asteroids is not between 1 and %d", count, maxWaveAsteroids)
+	}
+
+	var r = w.Region
+	if r.Width <= 0 || r.Height <= 0 {
+		return fmt.Errorf("region: %vx%v is empty", r.Width, r.Height)
+	}
+	if r.X < 0 || r.Y < 0 || r.X+r.Width > WorldWidth || r.Y+r.Height > WorldHeight {
+		return fmt.Errorf("region: %v is outside the %vx%v world", r, WorldWidth, WorldHeight)
+	}
+
+	if w.Speed.Min < 0 || w.Speed.Min > w.Speed.Max || w.Speed.Max > maxAsteroidSpeed {
+		return fmt.Errorf("speed: %v to %v is not a range within 0 and %v", w.Speed.Min, w.Speed.Max, maxAsteroidSpeed)
+	}
+
+	return nil
+}
+
+func (w *LevelWave) count() int32 {
+	return w.Large + w.Medium + w.Small + w.Random
+}
+
+// CurrentWave is the definition of the wave being played.
+func (g *Game) CurrentWave() *LevelWave {
+	var waves = g.Level.Waves
+	return &waves[min(max(int(g.Wave), 1), len(waves))-1]
+}
+
+// CheckWin ends the game in a win once the level's win condition is met,
+// given how many waves have been cleared.
+func (g *Game) CheckWin(wavesCleared int32) {
+	var win = g.Level.Win
+	if g.GameOver || !((win.Waves > 0 && wavesCleared >= win.Waves) || (win.Score > 0 && g.Score >= win.Score)) {
+		return
+	}
+	g.GameOver = true
+	g.Won = true
+	g.emit(Event{Type: LevelCompleted})
+}
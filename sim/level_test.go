@@ -0,0 +1,144 @@
+package sim
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLevel(t *testing.T) {
+	var tests = []struct {
+		name  string
+		json  string
+		error string
+	}{
+		{"unknown field", `{"name": "x", "wavez": []}`, "unknown field"},
+		{"no name", `{"waves": [{"large": 1, "region": {"width": 10, "height": 10}}]}`, "name"},
+		{"no waves", `{"name": "x"}`, "waves"},
+		{"empty wave", `{"name": "x", "waves": [{"region": {"width": 10, "height": 10}}]}`, "waves[0]."},
+		{"outside world", `{"name": "x", "waves": [{"small": 1, "region": {"x": 700, "width": 200, "height": 10}}]}`, "waves[0].region"},
+		{"backwards speed", `{"name": "x", "waves": [{"small": 1, "region": {"width": 10, "height": 10}, "speed": {"min": 50, "max": 10}}]}`, "waves[0].speed"},
+		{"too many splits", `{"name": "x", "waves": [{"small": 1, "region": {"width": 10, "height": 10}}], "splits": {"large": 20}}`, "splits.large"},
+		{"saucers never come", `{"name": "x", "waves": [{"small": 1, "region": {"width": 10, "height": 10}}], "saucers": {"first": 0}}`, "saucers"},
+	}
+	for _, test := range tests {
+		var _, err = LoadLevel([]byte(test.json))
+		if err == nil || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: got error %v, want one about %s", test.name, err, test.error)
+		}
+	}
+
+	var level, err = LoadLevel([]byte(`{"name": "x", "waves": [{"small": 1, "region": {"width": 10, "height": 10}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.Splits != ClassicLevel.Splits || level.Waves[0].Speed != ClassicLevel.Waves[0].Speed {
+		t.Errorf("level without splits or speed got splits %+v and speed %+v, want the classic ones", level.Splits, level.Waves[0].Speed)
+	}
+	level, err = LoadLevel([]byte(`{"name": "x", "waves": [{"small": 1, "region": {"width": 10, "height": 10}}], "splits": {"large": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.Splits != (Splits{Large: 3, Medium: ClassicLevel.Splits.Medium}) {
+		t.Errorf("level with only large splits got %+v", level.Splits)
+	}
+
+	if err := ClassicLevel.Validate(); err != nil {
+		t.Errorf("classic level is invalid: %v", err)
+	}
+
+	var files, _ = filepath.Glob("../assets/levels/*.json")
+	if len(files) == 0 {
+		t.Errorf("no levels found in assets/levels")
+	}
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLevel(contents); err != nil {
+			t.Errorf("%s: %v", file, err)
+		}
+	}
+}
+
+func TestLevelRules(t *testing.T) {
+	var level = &Level{
+		Name: "Test",
+		Waves: []LevelWave{
+			{Large: 2, Medium: 1, Region: NewRectangle(600, 300, 100, 100), Speed: Range{Min: 10, Max: 20}},
+			{Small: 3, Region: NewRectangle(0, 0, WorldWidth, WorldHeight), Speed: Range{Min: 30, Max: 30}},
+		},
+		Splits: Splits{Large: 3, Medium: 0},
+		Win:    WinCondition{Waves: 3},
+	}
+	var g = NewGame(1, Options{Level: level})
+
+	var counts = map[AsteroidSize]int{}
+	for _, a := range g.Asteroids.Items() {
+		counts[a.Size]++
+		if a.Position.X < 600 || a.Position.X > 700 || a.Position.Y < 300 || a.Position.Y > 400 {
+			t.Errorf("asteroid at %v spawned outside its region", a.Position)
+		}
+		if speed := Vector2Length(a.Velocity); speed < 9.9 || speed > 20.1 {
+			t.Errorf("asteroid spawned at speed %v, want 10 to 20", speed)
+		}
+	}
+	if counts[Large] != 2 || counts[Medium] != 1 || counts[Small] != 0 {
+		t.Errorf("first wave spawned %v, want 2 large and 1 medium", counts)
+	}
+	if g.Saucer != nil || g.SaucerInterval() != 0 {
+		t.Errorf("saucer scheduled in a level without any")
+	}
+
+	var large = g.Asteroids.Items()[0]
+	g.DestroyAsteroid(large, Vector2Zero())
+	g.DestroyAsteroid(g.Asteroids.Items()[2], Vector2Zero())
+	if count := g.Asteroids.Len(); count != 3+3 {
+		t.Errorf("splitting a large and a medium asteroid left %d, want 6", count)
+	}
+
+	// a large asteroid takes 1 shot plus 1 for each of its 3 medium pieces,
+	// which don't split, so the wave took 2*4+1 and 1+1 have been fired
+	g.Asteroids.RemoveIf(func(a *Asteroid) bool { return a.ShouldDelete })
+	if progress := g.WaveProgress(); progress < 2.0/9-0.001 || progress > 2.0/9+0.001 {
+		t.Errorf("wave progress %v after 2 of 9 shots, want %v", progress, 2.0/9)
+	}
+
+	// clear every wave up to the last, which repeats
+	for wave := int32(1); wave <= 3; wave++ {
+		if wave > 1 && g.Asteroids.Len() != 3 {
+			t.Errorf("wave %d started with %d asteroids, want the last wave's 3", wave, g.Asteroids.Len())
+		}
+		g.Asteroids.Clear()
+		g.ProcessWave(TickDuration)
+		for g.WaveTimer > 0 {
+			g.ProcessWave(TickDuration)
+		}
+	}
+	if !g.GameOver || !g.Won {
+		t.Errorf("clearing 3 waves didn't win, game over = %v and won = %v", g.GameOver, g.Won)
+	}
+}
+
+func TestReplayRecordsLevel(t *testing.T) {
+	var level = ClassicLevel
+	level.Name = "Changed"
+	level.Win.Score = 500
+	var replay = &Replay{Seed: 7, Options: Options{Level: &level}}
+	replay.Record(Input{Thrust: true})
+
+	var buffer bytes.Buffer
+	if err := replay.Encode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeReplay(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Options.Level.Name != "Changed" || decoded.Options.Level.Win.Score != 500 || len(decoded.Options.Level.Waves) != len(level.Waves) {
+		t.Errorf("level %+v came back as %+v", level, *decoded.Options.Level)
+	}
+}
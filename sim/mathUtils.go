@@ -0,0 +1,252 @@
+package sim
+
+import "math"
+
+const ratio = math.Pi / 180
+
+func RadToDeg(rad float64) (deg float64) {
+	deg = rad / (ratio)
+	return
+}
+
+func DegToRad(deg float32) (rad float32) {
+	rad = deg * (ratio)
+	return
+}
+
+func CheckCollisionRotatedRect(rectA Rectangle, rotA float32, rectB Rectangle, rotB float32) bool {
+	var a1, a2, a3, a4 = GetPointsFromRect(rectA)
+	var b1, b2, b3, b4 = GetPointsFromRect(rectB)
+
+	var transform = func(point Vector2, rotation float32, pos Vector2) Vector2 {
+		return Vector2Add(Vector2Scale(Vector2Rotate(Vector2Subtract(point, pos), DegToRad(rotation)), 1), pos)
+	}
+
+	var aPos = NewVector2(rectA.X, rectA.Y)
+	var aPoints = []Vector2{
+		transform(a1, rotA, aPos),
+		transform(a2, rotA, aPos),
+		transform(a3, rotA, aPos),
+		transform(a4, rotA, aPos),
+	}
+	var bPos = NewVector2(rectB.X, rectB.Y)
+	var bPoints = []Vector2{
+		transform(b1, rotB, bPos),
+		transform(b2, rotB, bPos),
+		transform(b3, rotB, bPos),
+		transform(b4, rotB, bPos),
+	}
+
+	return CheckCollisionPoly(aPoints, bPoints)
+}
+
+// CheckCollisionPoly reports whether two polygons overlap, including either
+// one lying wholly inside the other. Neither needs to be convex.
+func CheckCollisionPoly(pointsA []Vector2, pointsB []Vector2) bool {
+	var _, hit = CollidePolygons(pointsA, pointsB)
+	return hit
+}
+
+func CollisionPolyLine(points []Vector2, lineStart Vector2, lineEnd Vector2) bool {
+	// go through each of the vertices, plus the next
+	// vertex in the list
+	var next = 0
+	for current := 0; current < len(points); current++ {
+		// get next vertex in list
+		// if we've hit the end, wrap around to 0
+		next = current + 1
+		if next == len(points) {
+			next = 0
+		}
+
+		var lineStart2 = points[current]
+		var lineEnd2 = points[next]
+
+		var hitPoint = NewVector2(0, 0)
+		var hit bool = CheckCollisionLines(lineStart, lineEnd, lineStart2, lineEnd2, &hitPoint)
+		if hit {
+			return true
+		}
+	}
+
+	// never got a hit
+	return false
+}
+
+// CheckCollisionPointPoly is a port of rl.CheckCollisionPointPoly (crossing number test).
+func CheckCollisionPointPoly(point Vector2, points []Vector2) bool {
+	var inside = false
+	if len(points) > 2 {
+		for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+			if (points[i].Y > point.Y) != (points[j].Y > point.Y) &&
+				point.X < (points[j].X-points[i].X)*(point.Y-points[i].Y)/(points[j].Y-points[i].Y)+points[i].X {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// CheckCollisionLines is a port of rl.CheckCollisionLines, writing the
+// intersection to collisionPoint when the two segments cross.
+func CheckCollisionLines(startPos1 Vector2, endPos1 Vector2, startPos2 Vector2, endPos2 Vector2, collisionPoint *Vector2) bool {
+	var div = (endPos2.Y-startPos2.Y)*(endPos1.X-startPos1.X) - (endPos2.X-startPos2.X)*(endPos1.Y-startPos1.Y)
+	if math.Abs(float64(div)) < 1e-6 {
+		return false
+	}
+
+	var xi = ((startPos2.X-endPos2.X)*(startPos1.X*endPos1.Y-startPos1.Y*endPos1.X) - (startPos1.X-endPos1.X)*(startPos2.X*endPos2.Y-startPos2.Y*endPos2.X)) / div
+	var yi = ((startPos2.Y-endPos2.Y)*(startPos1.X*endPos1.Y-startPos1.Y*endPos1.X) - (startPos1.Y-endPos1.Y)*(startPos2.X*endPos2.Y-startPos2.Y*endPos2.X)) / div
+
+	if (math.Abs(float64(startPos1.X-endPos1.X)) > 1e-6 && (xi < min(startPos1.X, endPos1.X) || xi > max(startPos1.X, endPos1.X))) ||
+		(math.Abs(float64(startPos2.X-endPos2.X)) > 1e-6 && (xi < min(startPos2.X, endPos2.X) || xi > max(startPos2.X, endPos2.X))) ||
+		(math.Abs(float64(startPos1.Y-endPos1.Y)) > 1e-6 && (yi < min(startPos1.Y, endPos1.Y) || yi > max(startPos1.Y, endPos1.Y))) ||
+		(math.Abs(float64(startPos2.Y-endPos2.Y)) > 1e-6 && (yi < min(startPos2.Y, endPos2.Y) || yi > max(startPos2.Y, endPos2.Y))) {
+		return false
+	}
+
+	if collisionPoint != nil {
+		collisionPoint.X = xi
+		collisionPoint.Y = yi
+	}
+	return true
+}
+
+// CheckCollisionCirclePoly reports whether a circle overlaps a polygon, either
+// by its centre being inside or by an edge passing within radius.
+func CheckCollisionCirclePoly(center Vector2, radius float32, points []Vector2) bool {
+	if CheckCollisionPointPoly(center, points) {
+		return true
+	}
+
+	for i := range points {
+		if DistancePointSegment(center, points[i], points[(i+1)%len(points)]) <= radius {
+			return true
+		}
+	}
+
+	return false
+}
+
+func DistancePointSegment(point Vector2, start Vector2, end Vector2) float32 {
+	var segment = Vector2Subtract(end, start)
+	var lengthSqr = segment.X*segment.X + segment.Y*segment.Y
+	if lengthSqr == 0 {
+		return Vector2Length(Vector2Subtract(point, start))
+	}
+
+	var t = ((point.X-start.X)*segment.X + (point.Y-start.Y)*segment.Y) / lengthSqr
+	t = max(0, min(1, t))
+	return Vector2Length(Vector2Subtract(point, Vector2Add(start, Vector2Scale(segment, t))))
+}
+
+// WrappedDelta is the shortest step from a to b, which may cross a screen
+// edge.
+func WrappedDelta(a Vector2, b Vector2) Vector2 {
+	var delta = Vector2Subtract(b, a)
+	if delta.X > WorldWidth/2 {
+		delta.X -= WorldWidth
+	} else if delta.X < -WorldWidth/2 {
+		delta.X += WorldWidth
+	}
+	if delta.Y > WorldHeight/2 {
+		delta.Y -= WorldHeight
+	} else if delta.Y < -WorldHeight/2 {
+		delta.Y += WorldHeight
+	}
+	return delta
+}
+
+// WrappedDistance is the shortest distance between two points when the world
+// wraps around at its edges.
+func WrappedDistance(a Vector2, b Vector2) float32 {
+	return Vector2Length(WrappedDelta(a, b))
+}
+
+func GetPointsFromRect(rectangle Rectangle) (Vector2, Vector2, Vector2, Vector2) {
+	w := rectangle.Width / 2
+	h := rectangle.Height / 2
+	return NewVector2(rectangle.X-w, rectangle.Y+h),
+		NewVector2(rectangle.X+w, rectangle.Y+h),
+		NewVector2(rectangle.X+w, rectangle.Y-h),
+		NewVector2(rectangle.X-w, rectangle.Y-h)
+}
+
+func GetPointsFromRectSlice(rectangle Rectangle) []Vector2 {
+	var a1, a2, a3, a4 = GetPointsFromRect(rectangle)
+	return []Vector2{a1, a2, a3, a4}
+}
+
+func WrapCoordinates(position Vector2) (newPos Vector2) {
+	newPos.X = position.X
+	newPos.Y = position.Y
+	if position.X < 0.0 {
+		newPos.X = position.X + WorldWidth
+	}
+	if position.X >= WorldWidth {
+		newPos.X = position.X - WorldWidth
+	}
+
+	if position.Y < 0.0 {
+		newPos.Y = position.Y + WorldHeight
+	}
+	if position.Y >= WorldHeight {
+		newPos.Y = position.Y - WorldHeight
+	}
+	return newPos
+}
+
+// WrapOffsets lists where copies of something within radius of an edge must
+// be placed so it shows and collides on the opposite side too. The first
+// offset is always zero, for the original.
+func WrapOffsets(position Vector2, radius float32) (offsets [4]Vector2, count int) {
+	var dx, dy float32
+	if position.X < radius {
+		dx = WorldWidth
+	} else if position.X > WorldWidth-radius {
+		dx = -WorldWidth
+	}
+	if position.Y < radius {
+		dy = WorldHeight
+	} else if position.Y > WorldHeight-radius {
+		dy = -WorldHeight
+	}
+
+	offsets[0] = Vector2Zero()
+	count = 1
+	if dx != 0 {
+		offsets[count] = NewVector2(dx, 0)
+		count++
+	}
+	if dy != 0 {
+		offsets[count] = NewVector2(0, dy)
+		count++
+	}
+	if dx != 0 && dy != 0 {
+		offsets[count] = NewVector2(dx, dy)
+		count++
+	}
+	return offsets, count
+}
+
+// BoundingRadius is the radius of the smallest circle around the origin that
+// holds all the points once scaled.
+func BoundingRadius(points []Vector2, scale float32) float32 {
+	var radius float32 = 0
+	for _, point := range points {
+		radius = max(radius, Vector2Length(point))
+	}
+	return radius * scale
+}
+
+func Lerp(start float32, end float32, amount float32) float32 {
+	return start + amount*(end-start)
+}
+
+// Interpolate blends between the previous and current step for rendering. A
+// jump of more than half the world means the position wrapped, so it blends
+// the short way across the edge instead.
+func Interpolate(prev Vector2, current Vector2, alpha float32) Vector2 {
+	var delta = WrappedDelta(prev, current)
+	return WrapCoordinates(Vector2Add(prev, Vector2Scale(delta, alpha)))
+}
@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrapOffsets(t *testing.T) {
+	var tests = []struct {
+		name     string
+		position Vector2
+		offsets  []Vector2
+	}{
+		{"middle", NewVector2(400, 225), []Vector2{{}}},
+		{"left edge", NewVector2(5, 225), []Vector2{{}, {X: WorldWidth}}},
+		{"right edge", NewVector2(WorldWidth-5, 225), []Vector2{{}, {X: -WorldWidth}}},
+		{"top edge", NewVector2(400, 5), []Vector2{{}, {Y: WorldHeight}}},
+		{"bottom edge", NewVector2(400, WorldHeight-5), []Vector2{{}, {Y: -WorldHeight}}},
+		{"top left corner", NewVector2(5, 5), []Vector2{{}, {X: WorldWidth}, {Y: WorldHeight}, {X: WorldWidth, Y: WorldHeight}}},
+		{"bottom right corner", NewVector2(WorldWidth-5, WorldHeight-5), []Vector2{{}, {X: -WorldWidth}, {Y: -WorldHeight}, {X: -WorldWidth, Y: -WorldHeight}}},
+		{"just clear of the edge", NewVector2(10, 225), []Vector2{{}}},
+	}
+	for _, test := range tests {
+		var offsets, count = WrapOffsets(test.position, 10)
+		if !slices.Equal(offsets[:count], test.offsets) {
+			t.Errorf("%s: offsets %v, want %v", test.name, offsets[:count], test.offsets)
+		}
+	}
+}
+
+func TestWrappedDelta(t *testing.T) {
+	var tests = []struct {
+		name  string
+		a, b  Vector2
+		delta Vector2
+	}{
+		{"no wrap", NewVector2(100, 100), NewVector2(130, 60), NewVector2(30, -40)},
+		{"across the left edge", NewVector2(5, 100), NewVector2(WorldWidth-5, 100), NewVector2(-10, 0)},
+		{"across the right edge", NewVector2(WorldWidth-5, 100), NewVector2(5, 100), NewVector2(10, 0)},
+		{"across the top edge", NewVector2(100, 5), NewVector2(100, WorldHeight-5), NewVector2(0, -10)},
+		{"across the bottom edge", NewVector2(100, WorldHeight-5), NewVector2(100, 5), NewVector2(0, 10)},
+		{"across the corner", NewVector2(3, 4), NewVector2(WorldWidth-3, WorldHeight-4), NewVector2(-6, -8)},
+	}
+	for _, test := range tests {
+		if delta := WrappedDelta(test.a, test.b); delta != test.delta {
+			t.Errorf("%s: delta %v, want %v", test.name, delta, test.delta)
+		}
+	}
+	if distance := WrappedDistance(NewVector2(3, 4), NewVector2(WorldWidth-3, WorldHeight-4)); distance != 10 {
+		t.Errorf("distance across the corner is %v, want 10", distance)
+	}
+}
+
+func TestCollisionAcrossSeam(t *testing.T) {
+	var rng = NewRng(1)
+	var tests = []struct {
+		name string
+		a, b Vector2
+		hit  bool
+	}{
+		{"left and right edges", NewVector2(2, 225), NewVector2(WorldWidth-2, 225), true},
+		{"top and bottom edges", NewVector2(400, 2), NewVector2(400, WorldHeight-2), true},
+		{"opposite corners", NewVector2(2, 2), NewVector2(WorldWidth-2, WorldHeight-2), true},
+		{"half the world apart", NewVector2(2, 225), NewVector2(WorldWidth/2, 225), false},
+	}
+	for _, test := range tests {
+		var a = NewAsteroid(rng, test.a, 0, Medium, 0)
+		var b = NewAsteroid(rng, test.b, 0, Medium, 0)
+		a.UpdateCollisionPieces()
+		b.UpdateCollisionPieces()
+		if _, hit := b.CollidesWrapped(a.collisionPieces, a.Radius); hit != test.hit {
+			t.Errorf("%s: hit = %v, want %v", test.name, hit, test.hit)
+		}
+	}
+}
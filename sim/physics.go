@@ -0,0 +1,117 @@
+package sim
+
+import "math"
+
+// AsteroidDensity turns an asteroid's area into its mass.
+const AsteroidDensity float32 = 1
+
+// AsteroidRestitution is how much of their closing speed colliding asteroids
+// keep, 1 being perfectly elastic.
+const AsteroidRestitution float32 = 1
+
+// BulletImpulse is the momentum a bullet hands to the asteroid it hits, which
+// the fragments carry on with when the asteroid physics option is on.
+const BulletImpulse float32 = 15000
+
+// PolygonArea is the area of the polygon, whichever way round it is wound.
+func PolygonArea(points []Vector2) float32 {
+	var area float32 = 0
+	for i := range points {
+		area += cross(points[i], points[(i+1)%len(points)])
+	}
+	return float32(math.Abs(float64(area))) / 2
+}
+
+// UpdateMass sets the asteroid's mass from the area of its collision pieces,
+// which unlike the outline can't overlap themselves.
+func (a *Asteroid) UpdateMass() {
+	var area float32 = 0
+	for _, piece := range a.Pieces {
+		area += PolygonArea(piece)
+	}
+	a.Mass = max(1, area*a.Scale*a.Scale*AsteroidDensity)
+}
+
+// MomentOfInertia treats the asteroid as a disc filling its bounding circle.
+func (a *Asteroid) MomentOfInertia() float32 {
+	return a.Mass * a.Radius * a.Radius / 2
+}
+
+// ProcessAsteroidCollisions bounces touching asteroids apart. It expects the
+// broadphase and collision pieces to be up to date for asteroids.
+func (g *Game) ProcessAsteroidCollisions(asteroids []*Asteroid) {
+	for i, a := range asteroids {
+		if a.ShouldDelete {
+			continue
+		}
+		for _, j := range g.broadphase.Query(a.Position, a.Radius) {
+			var b = asteroids[j]
+			if j <= i || b.ShouldDelete || WrappedDistance(a.Position, b.Position) > a.Radius+b.Radius {
+				continue
+			}
+			if contact, hit := b.CollidesWrapped(a.collisionPieces, a.Radius); hit {
+				ResolveAsteroidCollision(b, a, contact)
+			}
+		}
+	}
+}
+
+// ResolveAsteroidCollision pushes two overlapping asteroids apart and swaps
+// momentum between them with an impulse at the contact point. The contact is
+// from a to b, with a possibly tested as a copy across a screen edge.
+func ResolveAsteroidCollision(a *Asteroid, b *Asteroid, contact Contact) {
+	var normal = contact.Normal
+	// b was tested where it really is, so a's copy is the one nearest b
+	var aPosition = Vector2Subtract(b.Position, WrappedDelta(a.Position, b.Position))
+	var ra = Vector2Subtract(contact.Point, aPosition)
+	var rb = Vector2Subtract(contact.Point, b.Position)
+
+	var inverseMassA = 1 / a.Mass
+	var inverseMassB = 1 / b.Mass
+	var inverseInertiaA = 1 / a.MomentOfInertia()
+	var inverseInertiaB = 1 / b.MomentOfInertia()
+
+	// split the overlap between them by mass so they no longer touch
+	var correction = contact.Depth / (inverseMassA + inverseMassB)
+	a.Position = WrapCoordinates(Vector2Subtract(a.Position, Vector2Scale(normal, correction*inverseMassA)))
+	b.Position = WrapCoordinates(Vector2Add(b.Position, Vector2Scale(normal, correction*inverseMassB)))
+
+	var spinA = DegToRad(a.AngularVelocity)
+	var spinB = DegToRad(b.AngularVelocity)
+	var velocityA = Vector2Add(a.Velocity, NewVector2(-spinA*ra.Y, spinA*ra.X))
+	var velocityB = Vector2Add(b.Velocity, NewVector2(-spinB*rb.Y, spinB*rb.X))
+	var closing = Vector2DotProduct(Vector2Subtract(velocityB, velocityA), normal)
+	if closing >= 0 {
+		return
+	}
+
+	var raCrossN = cross(ra, normal)
+	var rbCrossN = cross(rb, normal)
+	var impulse = -(1 + AsteroidRestitution) * closing /
+		(inverseMassA + inverseMassB + raCrossN*raCrossN*inverseInertiaA + rbCrossN*rbCrossN*inverseInertiaB)
+
+	a.Velocity = Vector2Subtract(a.Velocity, Vector2Scale(normal, impulse*inverseMassA))
+	b.Velocity = Vector2Add(b.Velocity, Vector2Scale(normal, impulse*inverseMassB))
+	a.AngularVelocity -= float32(RadToDeg(float64(raCrossN * impulse * inverseInertiaA)))
+	b.AngularVelocity += float32(RadToDeg(float64(rbCrossN * impulse * inverseInertiaB)))
+}
+
+// splitWithMomentum breaks an asteroid into fragments that carry on with its
+// velocity plus the kick from the bullet, fanned out evenly around the
+// direction of the shot so they fly apart instead of overlapping.
+// Each fragment also gets a random outward speed of up to spreadSteps times
+// spreadSpeed, like the random speed bonus of classic splits.
+func (g *Game) splitWithMomentum(a *Asteroid, impulse Vector2, count int, spreadSteps int32, spreadSpeed float32) {
+	var velocity = Vector2Add(a.Velocity, Vector2Scale(impulse, 1/a.Mass))
+	var heading = float32(RadToDeg(math.Atan2(float64(impulse.Y), float64(impulse.X))))
+
+	for k := range count {
+		var angle = heading + 180/float32(count) + float32(k)*360/float32(count) + g.Rng.GetRandomValueF(-20, 20)
+		var direction = Vector2Rotate(NewVector2(1, 0), DegToRad(angle))
+		var position = WrapCoordinates(Vector2Add(a.Position, Vector2Scale(direction, a.Radius/2)))
+
+		var fragment = g.SpawnAsteroid(position, angle, 0, a.Size-1)
+		fragment.Velocity = Vector2Add(velocity, Vector2Scale(direction, g.Rng.GetRandomValueF(0, spreadSteps)*spreadSpeed))
+		fragment.AngularVelocity = a.AngularVelocity + g.Rng.GetRandomValueF(-90, 90)
+	}
+}
@@ -0,0 +1,42 @@
+package sim
+
+import "testing"
+
+func TestResolveAsteroidCollisionConservesMomentum(t *testing.T) {
+	var rng = NewRng(1)
+	var a = NewAsteroid(rng, NewVector2(100, 200), 0, Medium, 0)
+	var b = NewAsteroid(rng, NewVector2(100+a.Radius*0.7, 205), 0, Small, 0)
+	a.Velocity = NewVector2(60, 0)
+	b.Velocity = NewVector2(-30, 10)
+	a.UpdateCollisionPieces()
+	b.UpdateCollisionPieces()
+
+	var contact, hit = b.CollidesWrapped(a.collisionPieces, a.Radius)
+	if !hit {
+		t.Fatalf("asteroids %v apart do not touch", WrappedDistance(a.Position, b.Position))
+	}
+
+	var momentum = func() Vector2 {
+		return Vector2Add(Vector2Scale(a.Velocity, a.Mass), Vector2Scale(b.Velocity, b.Mass))
+	}
+	var energy = func() float32 {
+		var linear = a.Mass*Vector2DotProduct(a.Velocity, a.Velocity) + b.Mass*Vector2DotProduct(b.Velocity, b.Velocity)
+		var spinA, spinB = DegToRad(a.AngularVelocity), DegToRad(b.AngularVelocity)
+		return (linear + a.MomentOfInertia()*spinA*spinA + b.MomentOfInertia()*spinB*spinB) / 2
+	}
+	var before, energyBefore = momentum(), energy()
+
+	ResolveAsteroidCollision(b, a, contact)
+
+	// compare as the velocity change of a, since momentum itself is large
+	var after = momentum()
+	if !near(before.X/a.Mass, after.X/a.Mass) || !near(before.Y/a.Mass, after.Y/a.Mass) {
+		t.Errorf("momentum %v became %v", before, after)
+	}
+	if energyAfter := energy(); energyAfter > energyBefore*1.001 || energyAfter < energyBefore*0.999 {
+		t.Errorf("kinetic energy %v became %v", energyBefore, energyAfter)
+	}
+	if Vector2DotProduct(Vector2Subtract(a.Velocity, b.Velocity), contact.Normal) < 0 {
+		t.Errorf("asteroids still moving into each other")
+	}
+}
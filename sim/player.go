@@ -0,0 +1,87 @@
+package sim
+
+// ShipOutline is the unscaled ship shape with the nose pointing along +Y.
+var ShipOutline = []Vector2{
+	NewVector2(0.0, 0.5),
+	NewVector2(-0.5, -0.5),
+	NewVector2(-0.3, -0.2),
+	NewVector2(0.3, -0.2),
+	NewVector2(0.5, -0.5),
+}
+
+var shipPieces = Decompose(ShipOutline)
+
+type PlayerShip struct {
+	Position     Vector2
+	PrevPosition Vector2
+	Rotation     float32
+	PrevRotation float32
+	Scale        float32
+	// Speed is the thrust acceleration in units per second squared
+	Speed    float32
+	Velocity Vector2
+	// Thrusting is whether the engine fired this step
+	Thrusting bool
+	// Invulnerable is the seconds of protection left after spawning
+	Invulnerable float32
+	// HyperspaceCooldown is the seconds left until the ship can jump again
+	HyperspaceCooldown float32
+	RenderPoints       []Vector2
+	// Pieces are the convex parts of the outline used for collision
+	Pieces [][]Vector2
+	Radius float32
+
+	collisionPieces [][]Vector2
+	wrappedPieces   [][]Vector2
+}
+
+func (p PlayerShip) GetBoundingBox() Rectangle {
+	return NewRectangle(p.Position.X, p.Position.Y, p.Scale, p.Scale)
+}
+
+func (p PlayerShip) GetScaledRenderPoints() []Vector2 {
+	var transform = func(point Vector2) Vector2 {
+		return Vector2Add(Vector2Scale(Vector2Rotate(point, DegToRad(p.Rotation-90)), p.Scale), p.Position)
+	}
+	var newPoints = make([]Vector2, len(p.RenderPoints))
+	for i, point := range p.RenderPoints {
+		newPoints[i] = transform(point)
+	}
+
+	return newPoints
+}
+
+// UpdateCollisionPieces moves the collision pieces to where the ship is now.
+func (p *PlayerShip) UpdateCollisionPieces() {
+	p.collisionPieces = TransformPieces(p.collisionPieces, p.Pieces, p.Position, p.Rotation-90, p.Scale)
+}
+
+func (p *PlayerShip) CollisionPieces() [][]Vector2 {
+	return p.collisionPieces
+}
+
+// SweepWrapped works like Asteroid.SweepWrapped.
+func (p *PlayerShip) SweepWrapped(start Vector2, end Vector2) (Sweep, bool) {
+	return sweepWrapped(start, end, p.collisionPieces, p.Position, p.Radius, &p.wrappedPieces)
+}
+
+// AllocateBuffers sizes the ship's slices up front so it never has to grow
+// them during play.
+func (p *PlayerShip) AllocateBuffers() {
+	p.collisionPieces = makePieces(len(shipPieces), len(ShipOutline))
+	p.wrappedPieces = makePieces(len(shipPieces), len(ShipOutline))
+}
+
+// Init resets a ship, keeping the slices of one being reused.
+func (p *PlayerShip) Init(position Vector2, rotation float32, scale float32, speed float32) {
+	*p = PlayerShip{Position: position, PrevPosition: position, Rotation: rotation, PrevRotation: rotation, Scale: scale, Speed: speed, collisionPieces: p.collisionPieces, wrappedPieces: p.wrappedPieces}
+	p.RenderPoints = ShipOutline
+	p.Pieces = shipPieces
+	p.Radius = BoundingRadius(ShipOutline, scale)
+}
+
+func NewPlayerShip(position Vector2, rotation float32, scale float32, speed float32) *PlayerShip {
+	var p = &PlayerShip{}
+	p.Init(position, rotation, scale, speed)
+	return p
+}
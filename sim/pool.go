@@ -0,0 +1,130 @@
+package sim
+
+import "slices"
+
+// Handle refers to an item in a Pool and stays valid for as long as the item
+// is alive. Once the item is removed its slot may be reused, and the
+// generation tells a stale handle apart from one to the new occupant.
+type Handle struct {
+	slot       int32
+	generation uint32
+}
+
+// Pool stores items whose memory is reused after they die, so spawning and
+// removing things during play doesn't allocate once the pool has grown to its
+// working size. Items never move, so pointers to them stay valid too.
+type Pool[T any] struct {
+	slots       []*T
+	generations []uint32
+	free        []int32
+
+	// items and itemSlots are the live items and their slots, in the order
+	// they were added
+	items     []*T
+	itemSlots []int32
+}
+
+// Reserve makes room for count items in total so the pool doesn't need to
+// grow until there are more than that alive at once. If prepare is not nil it
+// is called on each new item, to allocate anything the item will need.
+func (p *Pool[T]) Reserve(count int, prepare func(item *T)) {
+	var first = len(p.slots)
+	if count <= first {
+		return
+	}
+
+	p.items = slices.Grow(p.items, count-len(p.items))
+	p.itemSlots = slices.Grow(p.itemSlots, count-len(p.itemSlots))
+	p.free = slices.Grow(p.free, count-first)
+	// free slots are taken from the end, so push them in reverse to hand them
+	// out in order
+	for slot := count - 1; slot >= first; slot-- {
+		p.free = append(p.free, int32(slot))
+	}
+	for range count - first {
+		var item = new(T)
+		if prepare != nil {
+			prepare(item)
+		}
+		p.slots = append(p.slots, item)
+		p.generations = append(p.generations, 1)
+	}
+}
+
+// Add returns a live item, which may hold whatever a dead one left behind.
+// Callers reset every field, keeping slice capacity to reuse.
+func (p *Pool[T]) Add() (*T, Handle) {
+	var slot int32
+	if len(p.free) > 0 {
+		slot = p.free[len(p.free)-1]
+		p.free = p.free[:len(p.free)-1]
+	} else {
+		slot = int32(len(p.slots))
+		p.slots = append(p.slots, new(T))
+		// generations start at 1 so the zero Handle never refers to anything
+		p.generations = append(p.generations, 1)
+	}
+
+	var item = p.slots[slot]
+	p.items = append(p.items, item)
+	p.itemSlots = append(p.itemSlots, slot)
+	return item, Handle{slot: slot, generation: p.generations[slot]}
+}
+
+// Items is the live items in the order they were added. The slice is only
+// valid until the pool next changes.
+func (p *Pool[T]) Items() []*T {
+	return p.items
+}
+
+func (p *Pool[T]) Len() int {
+	return len(p.items)
+}
+
+// HandleAt is the handle of the item at index in Items.
+func (p *Pool[T]) HandleAt(index int) Handle {
+	var slot = p.itemSlots[index]
+	return Handle{slot: slot, generation: p.generations[slot]}
+}
+
+// Get returns the item for a handle, or nil if it has since been removed.
+func (p *Pool[T]) Get(handle Handle) *T {
+	if handle.slot < 0 || int(handle.slot) >= len(p.slots) || p.generations[handle.slot] != handle.generation {
+		return nil
+	}
+	return p.slots[handle.slot]
+}
+
+// RemoveIf removes every item dead reports true for, keeping the rest in
+// order.
+func (p *Pool[T]) RemoveIf(dead func(item *T) bool) {
+	var kept = 0
+	for i, item := range p.items {
+		var slot = p.itemSlots[i]
+		if dead(item) {
+			p.release(slot)
+			continue
+		}
+		p.items[kept] = item
+		p.itemSlots[kept] = slot
+		kept++
+	}
+	clear(p.items[kept:])
+	p.items = p.items[:kept]
+	p.itemSlots = p.itemSlots[:kept]
+}
+
+// Clear removes every item.
+func (p *Pool[T]) Clear() {
+	for _, slot := range p.itemSlots {
+		p.release(slot)
+	}
+	clear(p.items)
+	p.items = p.items[:0]
+	p.itemSlots = p.itemSlots[:0]
+}
+
+func (p *Pool[T]) release(slot int32) {
+	p.generations[slot]++
+	p.free = append(p.free, slot)
+}
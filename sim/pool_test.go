@@ -0,0 +1,76 @@
+package sim
+
+import "testing"
+
+func TestPoolHandles(t *testing.T) {
+	var pool Pool[Bullet]
+	var first, firstHandle = pool.Add()
+	var _, secondHandle = pool.Add()
+
+	if pool.Get(firstHandle) != first {
+		t.Fatalf("handle does not find its item")
+	}
+	if pool.Get(Handle{}) != nil {
+		t.Errorf("zero handle found an item")
+	}
+
+	first.ShouldDelete = true
+	pool.RemoveIf(func(b *Bullet) bool { return b.ShouldDelete })
+	if pool.Get(firstHandle) != nil {
+		t.Errorf("handle to a removed item still finds it")
+	}
+	if pool.HandleAt(0) != secondHandle {
+		t.Errorf("survivor's handle changed after removal")
+	}
+
+	var reused, reusedHandle = pool.Add()
+	if reused != first {
+		t.Errorf("freed slot was not reused")
+	}
+	if pool.Get(firstHandle) != nil || pool.Get(reusedHandle) != reused {
+		t.Errorf("stale handle finds the slot's new occupant")
+	}
+}
+
+// scriptedInput flies round shooting so asteroids keep splitting, waves keep
+// clearing and the ship keeps dying and respawning.
+func scriptedInput(tick int) Input {
+	return Input{
+		Thrust:     tick/TickRate%3 == 0,
+		RotateLeft: tick/(TickRate*2)%2 == 0,
+		Fire:       tick%10 == 0,
+	}
+}
+
+func playingGame(options Options) *Game {
+	var g = NewGame(1, options)
+	g.Lives = 1 << 20
+	// play long enough for the pools and buffers to reach their working size
+	for tick := range 60 * TickRate {
+		g.Step(scriptedInput(tick))
+	}
+	return g
+}
+
+func TestStepDoesNotAllocate(t *testing.T) {
+	for _, options := range []Options{{}, {AsteroidPhysics: true}} {
+		var g = playingGame(options)
+		var tick = 0
+		var allocs = testing.AllocsPerRun(10*TickRate, func() {
+			g.Step(scriptedInput(tick))
+			tick++
+		})
+		if allocs != 0 {
+			t.Errorf("Step with %+v allocates %v times per call", options, allocs)
+		}
+	}
+}
+
+func BenchmarkStep(b *testing.B) {
+	var g = playingGame(Options{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for tick := range b.N {
+		g.Step(scriptedInput(tick))
+	}
+}
@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReplayVersion is bumped whenever the file layout or the game rules change,
+// since an old recording would no longer play out the same way.
+const ReplayVersion uint16 = 13
+
+var replayMagic = [4]byte{'S', 'D', 'R', 'P'}
+
+var ErrNotReplay = errors.New("replay: not a replay file")
+
+// Replay is everything needed to reproduce a game: the seed and options it
+// started with and the input of every step. The whole level is recorded, so
+// the replay still plays back after its file is changed or removed.
+type Replay struct {
+	Seed    uint64
+	Options Options
+	Inputs  []Input
+}
+
+const (
+	optionAsteroidPhysics uint8 = 1 << iota
+)
+
+func (o Options) bits() uint8 {
+	var bits uint8
+	if o.AsteroidPhysics {
+		bits |= optionAsteroidPhysics
+	}
+	return bits
+}
+
+func optionsFromBits(bits uint8, hyperspaceRisk uint8) Options {
+	return Options{
+		AsteroidPhysics: bits&optionAsteroidPhysics != 0,
+		HyperspaceRisk:  hyperspaceRisk,
+	}
+}
+
+const (
+	inputThrust uint8 = 1 << iota
+	inputRotateLeft
+	inputRotateRight
+	inputFire
+	inputHyperspace
+	inputPause
+	inputRestart
+)
+
+func (i Input) bits() uint8 {
+	var bits uint8
+	if i.Thrust {
+		bits |= inputThrust
+	}
+	if i.RotateLeft {
+		bits |= inputRotateLeft
+	}
+	if i.RotateRight {
+		bits |= inputRotateRight
+	}
+	if i.Fire {
+		bits |= inputFire
+	}
+	if i.Hyperspace {
+		bits |= inputHyperspace
+	}
+	if i.Pause {
+		bits |= inputPause
+	}
+	if i.Restart {
+		bits |= inputRestart
+	}
+	return bits
+}
+
+func inputFromBits(bits uint8, turn int8) Input {
+	return Input{
+		Thrust:      bits&inputThrust != 0,
+		RotateLeft:  bits&inputRotateLeft != 0,
+		RotateRight: bits&inputRotateRight != 0,
+		Turn:        turn,
+		Fire:        bits&inputFire != 0,
+		Hyperspace:  bits&inputHyperspace != 0,
+		Pause:       bits&inputPause != 0,
+		Restart:     bits&inputRestart != 0,
+	}
+}
+
+func (r *Replay) Record(input Input) {
+	r.Inputs = append(r.Inputs, input)
+}
+
+// maxReplayLevelSize caps the level read from a replay header.
+const maxReplayLevelSize = 1 << 20
+
+// Encode writes the replay as a header of the seed, option flags and level as
+// JSON followed by run-length encoded input bitmasks and analog turn values,
+// since the same keys are usually held for many steps in a row.
+func (r *Replay) Encode(w io.Writer) error {
+	var level = r.Options.Level
+	if level == nil {
+		level = &ClassicLevel
+	}
+	var levelJSON, err = json.Marshal(level)
+	if err != nil {
+		return err
+	}
+
+	var out = bufio.NewWriter(w)
+	out.Write(replayMagic[:])
+	binary.Write(out, binary.LittleEndian, ReplayVersion)
+	binary.Write(out, binary.LittleEndian, r.Seed)
+	out.WriteByte(r.Options.bits())
+	out.WriteByte(r.Options.HyperspaceRisk)
+	binary.Write(out, binary.LittleEndian, uint32(len(levelJSON)))
+	out.Write(levelJSON)
+	binary.Write(out, binary.LittleEndian, uint32(len(r.Inputs)))
+
+	var buf [binary.MaxVarintLen64]byte
+	for i := 0; i < len(r.Inputs); {
+		var input = r.Inputs[i]
+		var run = 1
+		for i+run < len(r.Inputs) && r.Inputs[i+run] == input {
+			run++
+		}
+		out.WriteByte(input.bits())
+		out.WriteByte(byte(input.Turn))
+		out.Write(buf[:binary.PutUvarint(buf[:], uint64(run))])
+		i += run
+	}
+
+	return out.Flush()
+}
+
+func DecodeReplay(r io.Reader) (*Replay, error) {
+	var in = bufio.NewReader(r)
+
+	var magic [4]byte
+	if _, err := io.ReadFull(in, magic[:]); err != nil || magic != replayMagic {
+		return nil, ErrNotReplay
+	}
+
+	var version uint16
+	if err := binary.Read(in, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+	if version != ReplayVersion {
+		return nil, fmt.Errorf("replay: unsupported version %d", version)
+	}
+
+	var replay = &Replay{}
+	var count uint32
+	if err := binary.Read(in, binary.LittleEndian, &replay.Seed); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+	var options [2]byte
+	if _, err := io.ReadFull(in, options[:]); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+	replay.Options = optionsFromBits(options[0], options[1])
+
+	var levelSize uint32
+	if err := binary.Read(in, binary.LittleEndian, &levelSize); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+	if levelSize > maxReplayLevelSize {
+		return nil, fmt.Errorf("replay: level of %d bytes is too large", levelSize)
+	}
+	var levelJSON = make([]byte, levelSize)
+	if _, err := io.ReadFull(in, levelJSON); err != nil {
+		return nil, fmt.Errorf("replay: reading level: %w", err)
+	}
+	level, err := LoadLevel(levelJSON)
+	if err != nil {
+		return nil, fmt.Errorf("replay: invalid level: %w", err)
+	}
+	replay.Options.Level = level
+
+	if err := binary.Read(in, binary.LittleEndian, &count); err != nil {
+		return nil, fmt.Errorf("replay: reading header: %w", err)
+	}
+
+	// the count comes from the file, so don't trust it for a huge allocation
+	replay.Inputs = make([]Input, 0, min(count, 1<<20))
+	for uint32(len(replay.Inputs)) < count {
+		var bits, err = in.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("replay: truncated after %d of %d steps", len(replay.Inputs), count)
+		}
+		turn, err := in.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("replay: truncated after %d of %d steps", len(replay.Inputs), count)
+		}
+		run, err := binary.ReadUvarint(in)
+		if err != nil || run == 0 || uint64(len(replay.Inputs))+run > uint64(count) {
+			return nil, fmt.Errorf("replay: corrupt run after %d of %d steps", len(replay.Inputs), count)
+		}
+		var input = inputFromBits(bits, int8(turn))
+		for range run {
+			replay.Inputs = append(replay.Inputs, input)
+		}
+	}
+
+	return replay, nil
+}
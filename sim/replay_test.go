@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReplayPlaysBack(t *testing.T) {
+	var options = Options{AsteroidPhysics: true, HyperspaceRisk: 30}
+	var recorded = NewGame(99, options)
+	var replay = &Replay{Seed: 99, Options: options}
+	for tick := range 30 * TickRate {
+		var input = scriptedInput(tick)
+		input.Turn = int8(tick % 7 * 10)
+		replay.Record(input)
+		recorded.Step(input)
+	}
+
+	var buffer bytes.Buffer
+	if err := replay.Encode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeReplay(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Seed != replay.Seed || decoded.Options.bits() != options.bits() || decoded.Options.HyperspaceRisk != options.HyperspaceRisk {
+		t.Errorf("header came back as seed %d and options %+v", decoded.Seed, decoded.Options)
+	}
+	if len(decoded.Inputs) != len(replay.Inputs) {
+		t.Fatalf("decoded %d steps, want %d", len(decoded.Inputs), len(replay.Inputs))
+	}
+	for i, input := range decoded.Inputs {
+		if input != replay.Inputs[i] {
+			t.Fatalf("step %d came back as %+v, want %+v", i, input, replay.Inputs[i])
+		}
+	}
+
+	var played = NewGame(decoded.Seed, decoded.Options)
+	for _, input := range decoded.Inputs {
+		played.Step(input)
+	}
+	if difference := stateDifference(recorded, played); difference != "" {
+		t.Errorf("replay played out differently: %s", difference)
+	}
+}
+
+func TestDecodeReplayRejectsBadInput(t *testing.T) {
+	var replay = &Replay{Seed: 5}
+	for tick := range 10 * TickRate {
+		replay.Record(scriptedInput(tick))
+	}
+	var buffer bytes.Buffer
+	if err := replay.Encode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	var encoded = buffer.Bytes()
+
+	if _, err := DecodeReplay(bytes.NewReader([]byte("SDRQ" + string(encoded[4:])))); !errors.Is(err, ErrNotReplay) {
+		t.Errorf("bad magic gave error %v, want ErrNotReplay", err)
+	}
+	if _, err := DecodeReplay(bytes.NewReader(nil)); !errors.Is(err, ErrNotReplay) {
+		t.Errorf("empty file gave error %v, want ErrNotReplay", err)
+	}
+	for _, size := range []int{5, 12, 20, len(encoded) / 2, len(encoded) - 1} {
+		if _, err := DecodeReplay(bytes.NewReader(encoded[:size])); err == nil {
+			t.Errorf("replay cut to %d of %d bytes decoded without an error", size, len(encoded))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package sim
+
+import "math/rand/v2"
+
+// Rng is the only source of randomness in the simulation. Two games created
+// with the same seed and fed the same inputs make exactly the same decisions.
+type Rng struct {
+	Seed uint64
+	pcg  *rand.PCG
+	rand *rand.Rand
+}
+
+func NewRng(seed uint64) *Rng {
+	var pcg = rand.NewPCG(seed, seed)
+	return &Rng{Seed: seed, pcg: pcg, rand: rand.New(pcg)}
+}
+
+// GetRandomValue matches rl.GetRandomValue: both bounds are inclusive.
+func (r *Rng) GetRandomValue(min int32, max int32) int32 {
+	if min > max {
+		min, max = max, min
+	}
+	return min + r.rand.Int32N(max-min+1)
+}
+
+func (r *Rng) GetRandomValueF(min int32, max int32) float32 {
+	return float32(r.GetRandomValue(min, max))
+}
+
+func (r *Rng) GetRandomAngle() float32 {
+	return r.GetRandomValueF(-360, 360)
+}
+
+// MarshalBinary snapshots the generator state so it can be restored later
+// with UnmarshalBinary.
+func (r *Rng) MarshalBinary() ([]byte, error) {
+	return r.pcg.MarshalBinary()
+}
+
+func (r *Rng) UnmarshalBinary(data []byte) error {
+	return r.pcg.UnmarshalBinary(data)
+}
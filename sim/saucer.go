@@ -0,0 +1,297 @@
+package sim
+
+import "math"
+
+type SaucerSize int32
+
+const (
+	LargeSaucer SaucerSize = iota
+	SmallSaucer
+)
+
+func (s SaucerSize) Name() string {
+	switch s {
+	case LargeSaucer:
+		return "Large Saucer"
+	case SmallSaucer:
+		return "Small Saucer"
+	}
+
+	return "Unknown"
+}
+
+// Points is the bonus for shooting down a saucer of this size.
+func (s SaucerSize) Points() int32 {
+	switch s {
+	case LargeSaucer:
+		return 200
+	case SmallSaucer:
+		return 1000
+	}
+
+	return 0
+}
+
+// SaucerOutline is the unscaled saucer shape, a dome on top of a flat hull.
+var SaucerOutline = []Vector2{
+	NewVector2(-1, 0),
+	NewVector2(-0.45, -0.3),
+	NewVector2(-0.2, -0.65),
+	NewVector2(0.2, -0.65),
+	NewVector2(0.45, -0.3),
+	NewVector2(1, 0),
+	NewVector2(0.45, 0.35),
+	NewVector2(-0.45, 0.35),
+}
+
+var saucerPieces = Decompose(SaucerOutline)
+
+// SaucerBulletSpeed is in units per second and SaucerBulletLifetime in
+// seconds, a little shorter ranged than the ship's shots.
+const SaucerBulletSpeed float32 = 300
+const SaucerBulletLifetime float32 = 1.5
+
+type Saucer struct {
+	Position     Vector2
+	PrevPosition Vector2
+	// Velocity is in units per second
+	Velocity Vector2
+	Size     SaucerSize
+	Scale    float32
+	Radius   float32
+	// Pieces are the convex parts of the outline used for collision
+	Pieces [][]Vector2
+	// Travelled is how far the saucer has flown across the screen. It leaves
+	// once it has crossed the whole width.
+	Travelled float32
+	// FireTimer and TurnTimer count down to the next shot and change of
+	// course, in seconds
+	FireTimer float32
+	TurnTimer float32
+
+	collisionPieces [][]Vector2
+	wrappedPieces   [][]Vector2
+}
+
+func (s *Saucer) Speed() float32 {
+	if s.Size == SmallSaucer {
+		return 110
+	}
+	return 80
+}
+
+func (s *Saucer) FireInterval() float32 {
+	if s.Size == SmallSaucer {
+		return 1.2
+	}
+	return 1.5
+}
+
+// Init resets a saucer to fly across the screen from position, to the right
+// if direction is 1 or the left if it is -1. One being reused keeps its
+// slices.
+func (s *Saucer) Init(position Vector2, size SaucerSize, direction float32) {
+	*s = Saucer{Position: position, PrevPosition: position, Size: size, collisionPieces: s.collisionPieces, wrappedPieces: s.wrappedPieces}
+	s.Scale = 16
+	if size == SmallSaucer {
+		s.Scale = 9
+	}
+	s.Velocity = NewVector2(direction*s.Speed(), 0)
+	s.Pieces = saucerPieces
+	s.Radius = BoundingRadius(SaucerOutline, s.Scale)
+	s.FireTimer = s.FireInterval()
+}
+
+// AllocateBuffers sizes the saucer's slices up front so it never has to grow
+// them during play.
+func (s *Saucer) AllocateBuffers() {
+	s.collisionPieces = makePieces(len(saucerPieces), len(SaucerOutline))
+	s.wrappedPieces = makePieces(len(saucerPieces), len(SaucerOutline))
+}
+
+// UpdateCollisionPieces moves the collision pieces to where the saucer is now.
+func (s *Saucer) UpdateCollisionPieces() {
+	s.collisionPieces = TransformPieces(s.collisionPieces, s.Pieces, s.Position, 0, s.Scale)
+}
+
+// CollidesWrapped works like Asteroid.CollidesWrapped.
+func (s *Saucer) CollidesWrapped(pieces [][]Vector2, radius float32) (Contact, bool) {
+	return collideWrapped(s.collisionPieces, s.Position, s.Radius, pieces, radius, &s.wrappedPieces)
+}
+
+// SweepWrapped works like Asteroid.SweepWrapped.
+func (s *Saucer) SweepWrapped(start Vector2, end Vector2) (Sweep, bool) {
+	return sweepWrapped(start, end, s.collisionPieces, s.Position, s.Radius, &s.wrappedPieces)
+}
+
+// LeadTarget is the point to aim at from shooter for a shot at speed to meet
+// a target moving at velocity, or false if the shot can never catch it.
+func LeadTarget(shooter Vector2, target Vector2, velocity Vector2, speed float32) (Vector2, bool) {
+	// solve |offset + velocity*t| = speed*t for the earliest t >= 0
+	var offset = Vector2Subtract(target, shooter)
+	var a = Vector2DotProduct(velocity, velocity) - speed*speed
+	var b = 2 * Vector2DotProduct(offset, velocity)
+	var c = Vector2DotProduct(offset, offset)
+
+	var t float32
+	if float32(math.Abs(float64(a))) < 1e-6 {
+		if b >= 0 {
+			return Vector2{}, false
+		}
+		t = -c / b
+	} else {
+		var discriminant = b*b - 4*a*c
+		if discriminant < 0 {
+			return Vector2{}, false
+		}
+		var root = float32(math.Sqrt(float64(discriminant)))
+		var t1 = (-b - root) / (2 * a)
+		var t2 = (-b + root) / (2 * a)
+		t = min(t1, t2)
+		if t < 0 {
+			t = max(t1, t2)
+		}
+		if t < 0 {
+			return Vector2{}, false
+		}
+	}
+
+	return Vector2Add(target, Vector2Scale(velocity, t)), true
+}
+
+// SaucerInterval is how long to wait before sending in the next saucer, going
+// by the level's schedule.
+func (g *Game) SaucerInterval() float32 {
+	var schedule = g.Level.Saucers
+	if schedule == nil {
+		return 0
+	}
+	return max(schedule.Min, schedule.First-schedule.Decrease*float32(g.Wave-1))
+}
+
+// SmallSaucerChance is the percentage chance of the next saucer being a small
+// one, which goes up with the score.
+func (g *Game) SmallSaucerChance() int32 {
+	return min(90, 10+g.Score/250)
+}
+
+// SpawnSaucer sends a saucer in from the left or right edge.
+func (g *Game) SpawnSaucer() {
+	var size = LargeSaucer
+	if g.Rng.GetRandomValue(1, 100) <= g.SmallSaucerChance() {
+		size = SmallSaucer
+	}
+
+	var direction float32 = 1
+	var x float32 = 0
+	if g.Rng.GetRandomValue(0, 1) == 1 {
+		direction = -1
+		x = WorldWidth - 1
+	}
+	var y = g.Rng.GetRandomValueF(int32(WorldHeight/10), int32(WorldHeight*9/10))
+
+	g.saucer.Init(NewVector2(x, y), size, direction)
+	g.saucer.TurnTimer = g.saucerTurnTime()
+	g.Saucer = &g.saucer
+}
+
+// ProcessSaucer counts down to the next saucer, then flies it across the
+// screen in a zig-zag, shooting as it goes.
+func (g *Game) ProcessSaucer(dt float32) {
+	if g.Saucer == nil {
+		if g.Level.Saucers == nil {
+			return
+		}
+		g.SaucerTimer -= dt
+		if g.SaucerTimer <= 0 {
+			g.SpawnSaucer()
+		}
+		return
+	}
+
+	var saucer = g.Saucer
+	saucer.Position = WrapCoordinates(Vector2Add(saucer.Position, Vector2Scale(saucer.Velocity, dt)))
+	saucer.Travelled += float32(math.Abs(float64(saucer.Velocity.X))) * dt
+	if saucer.Travelled >= WorldWidth {
+		g.Saucer = nil
+		g.SaucerTimer = g.SaucerInterval()
+		return
+	}
+
+	saucer.TurnTimer -= dt
+	if saucer.TurnTimer <= 0 {
+		// dive, climb or fly level, never straight up or down
+		saucer.Velocity.Y = float32(g.Rng.GetRandomValue(-1, 1)) * saucer.Speed() * 0.75
+		saucer.TurnTimer = g.saucerTurnTime()
+	}
+
+	saucer.FireTimer -= dt
+	if saucer.FireTimer <= 0 {
+		var aim = g.saucerAim(saucer)
+		var bullet = g.SpawnBullet(saucer.Position, aim, SaucerBulletSpeed)
+		bullet.Lifetime = SaucerBulletLifetime
+		bullet.FromSaucer = true
+		g.emit(Event{Type: ShotFired, Position: saucer.Position, Velocity: saucer.Velocity, Rotation: aim})
+		saucer.FireTimer = saucer.FireInterval()
+	}
+}
+
+func (g *Game) saucerTurnTime() float32 {
+	return g.Rng.GetRandomValueF(50, 150) / 100
+}
+
+// saucerAim is the angle a saucer fires at. The large one shoots anywhere,
+// while the small one leads the ship, missing by less as the score goes up.
+func (g *Game) saucerAim(saucer *Saucer) float32 {
+	if saucer.Size == LargeSaucer || g.Player == nil {
+		return g.Rng.GetRandomValueF(0, 359)
+	}
+
+	var target, ok = LeadTarget(saucer.Position, g.Player.Position, g.Player.Velocity, SaucerBulletSpeed)
+	if !ok {
+		target = g.Player.Position
+	}
+	var toTarget = Vector2Subtract(target, saucer.Position)
+	var angle = float32(RadToDeg(math.Atan2(float64(toTarget.Y), float64(toTarget.X))))
+
+	var spread = max(2, 30-g.Score/500)
+	return angle + g.Rng.GetRandomValueF(-spread, spread)
+}
+
+// DestroySaucer blows up the saucer, leaving any scoring to the caller.
+func (g *Game) DestroySaucer() {
+	var saucer = g.Saucer
+	g.emit(Event{Type: SaucerDestroyed, Position: saucer.Position, Velocity: saucer.Velocity, Scale: saucer.Scale, Outline: SaucerOutline})
+	g.Saucer = nil
+	g.SaucerTimer = g.SaucerInterval()
+}
+
+// ProcessSaucerCollision crashes the saucer into the ship or an asteroid,
+// taking both out.
+func (g *Game) ProcessSaucerCollision(asteroids []*Asteroid) {
+	var saucer = g.Saucer
+	if saucer == nil {
+		return
+	}
+
+	if player := g.Player; player != nil && player.Invulnerable <= 0 && WrappedDistance(player.Position, saucer.Position) <= player.Radius+saucer.Radius {
+		if _, hit := saucer.CollidesWrapped(player.CollisionPieces(), player.Radius); hit {
+			g.AddScore(saucer.Size.Points())
+			g.DestroySaucer()
+			g.KillPlayer()
+			return
+		}
+	}
+
+	for _, i := range g.broadphase.Query(saucer.Position, saucer.Radius) {
+		var a = asteroids[i]
+		if a.ShouldDelete || WrappedDistance(a.Position, saucer.Position) > a.Radius+saucer.Radius {
+			continue
+		}
+		if _, hit := a.CollidesWrapped(saucer.collisionPieces, saucer.Radius); hit {
+			g.DestroyAsteroid(a, Vector2Zero())
+			g.DestroySaucer()
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package sim
+
+import "testing"
+
+func TestLeadTarget(t *testing.T) {
+	var tests = []struct {
+		name     string
+		target   Vector2
+		velocity Vector2
+		speed    float32
+		ok       bool
+	}{
+		{name: "still", target: NewVector2(100, 0), speed: 300, ok: true},
+		{name: "crossing", target: NewVector2(200, 0), velocity: NewVector2(0, 120), speed: 300, ok: true},
+		{name: "coming closer", target: NewVector2(200, 50), velocity: NewVector2(-150, 0), speed: 300, ok: true},
+		{name: "as fast as the shot", target: NewVector2(-100, 40), velocity: NewVector2(300, 0), speed: 300, ok: true},
+		{name: "outrunning the shot", target: NewVector2(100, 0), velocity: NewVector2(400, 0), speed: 300},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var shooter = NewVector2(0, 0)
+			var aim, ok = LeadTarget(shooter, test.target, test.velocity, test.speed)
+			if ok != test.ok {
+				t.Fatalf("ok = %v, want %v", ok, test.ok)
+			}
+			if !ok {
+				return
+			}
+
+			// the shot and the target must get to the aim point together
+			var shotTime = Vector2Length(Vector2Subtract(aim, shooter)) / test.speed
+			var targetAt = Vector2Add(test.target, Vector2Scale(test.velocity, shotTime))
+			if Vector2Length(Vector2Subtract(targetAt, aim)) > 0.01 {
+				t.Errorf("target is at %v when the shot reaches %v", targetAt, aim)
+			}
+		})
+	}
+}
+
+func TestSaucerBullets(t *testing.T) {
+	var g = NewGame(1, Options{})
+	g.Asteroids.Clear()
+	var asteroid, _ = g.Asteroids.Add()
+	asteroid.Init(g.Rng, NewVector2(100, 200), 0, Large, 0)
+	g.Player.Invulnerable = 0
+	g.Player.Position = NewVector2(300, 200)
+
+	var shoot = func(from Vector2, rotation float32, fromSaucer bool) *Bullet {
+		var bullet = g.SpawnBullet(Vector2Add(from, NewVector2(2, 0)), rotation, SaucerBulletSpeed)
+		bullet.PrevPosition = from
+		bullet.FromSaucer = fromSaucer
+		return bullet
+	}
+
+	// outlines aren't always around their centre, so sweep right across the
+	// asteroid through the middle of one of its convex pieces
+	asteroid.UpdateCollisionPieces()
+	var piece = asteroid.collisionPieces[0]
+	var middle = Vector2Zero()
+	for _, point := range piece {
+		middle = Vector2Add(middle, point)
+	}
+	middle = Vector2Scale(middle, 1/float32(len(piece)))
+	var bullet = shoot(NewVector2(middle.X+asteroid.Radius*2, middle.Y), 0, true)
+	bullet.PrevPosition = NewVector2(middle.X-asteroid.Radius*2, middle.Y)
+	g.ProcessCollision()
+	if !asteroid.ShouldDelete || g.Score != 0 {
+		t.Errorf("saucer shot: asteroid destroyed = %v and score %d, want destroyed and no score", asteroid.ShouldDelete, g.Score)
+	}
+
+	g.SpawnSaucer()
+	g.Saucer.Position = NewVector2(500, 200)
+	shoot(g.Saucer.Position, 0, true)
+	g.ProcessCollision()
+	if g.Saucer == nil {
+		t.Fatalf("saucer shot down by its own bullet")
+	}
+
+	var size = g.Saucer.Size
+	shoot(g.Saucer.Position, 0, false)
+	g.ProcessCollision()
+	if g.Saucer != nil || g.Score != size.Points() {
+		t.Errorf("ship shot: saucer alive = %v and score %d, want shot down for %d", g.Saucer != nil, g.Score, size.Points())
+	}
+
+	shoot(g.Player.Position, 0, true)
+	g.ProcessCollision()
+	if g.Player != nil {
+		t.Errorf("saucer shot missed the ship")
+	}
+}
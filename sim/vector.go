@@ -0,0 +1,68 @@
+package sim
+
+import "math"
+
+// Vector2 mirrors rl.Vector2 field for field so the front end can convert
+// between the two with a plain type conversion.
+type Vector2 struct {
+	X float32
+	Y float32
+}
+
+func NewVector2(x, y float32) Vector2 {
+	return Vector2{X: x, Y: y}
+}
+
+func Vector2Zero() Vector2 {
+	return Vector2{}
+}
+
+func Vector2Add(v1, v2 Vector2) Vector2 {
+	return Vector2{X: v1.X + v2.X, Y: v1.Y + v2.Y}
+}
+
+func Vector2Subtract(v1, v2 Vector2) Vector2 {
+	return Vector2{X: v1.X - v2.X, Y: v1.Y - v2.Y}
+}
+
+func Vector2Scale(v Vector2, scale float32) Vector2 {
+	return Vector2{X: v.X * scale, Y: v.Y * scale}
+}
+
+func Vector2Length(v Vector2) float32 {
+	return float32(math.Sqrt(float64(v.X*v.X + v.Y*v.Y)))
+}
+
+func Vector2DotProduct(v1, v2 Vector2) float32 {
+	return v1.X*v2.X + v1.Y*v2.Y
+}
+
+func Vector2Normalize(v Vector2) Vector2 {
+	var length = Vector2Length(v)
+	if length > 0 {
+		return Vector2Scale(v, 1/length)
+	}
+	return v
+}
+
+func Vector2Lerp(v1, v2 Vector2, amount float32) Vector2 {
+	return Vector2{X: v1.X + amount*(v2.X-v1.X), Y: v1.Y + amount*(v2.Y-v1.Y)}
+}
+
+func Vector2Rotate(v Vector2, angle float32) Vector2 {
+	var cos = float32(math.Cos(float64(angle)))
+	var sin = float32(math.Sin(float64(angle)))
+	return Vector2{X: v.X*cos - v.Y*sin, Y: v.X*sin + v.Y*cos}
+}
+
+// Rectangle mirrors rl.Rectangle.
+type Rectangle struct {
+	X      float32
+	Y      float32
+	Width  float32
+	Height float32
+}
+
+func NewRectangle(x, y, width, height float32) Rectangle {
+	return Rectangle{X: x, Y: y, Width: width, Height: height}
+}
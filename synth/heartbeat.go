@@ -0,0 +1,40 @@
+package synth
+
+// The heartbeat starts SlowestBeat seconds apart and speeds up to FastestBeat
+// as the wave is cleared.
+const SlowestBeat float32 = 1
+const FastestBeat float32 = 0.25
+
+// BeatInterval is the time between beats with progress of the wave cleared,
+// from 0 to 1.
+func BeatInterval(progress float32) float32 {
+	progress = max(0, min(1, progress))
+	return SlowestBeat + (FastestBeat-SlowestBeat)*progress
+}
+
+// Heartbeat times the two alternating thumps that play under a wave, like the
+// original arcade game.
+type Heartbeat struct {
+	timer float32
+	// High is which of the two tones the last beat was
+	High bool
+}
+
+func (h *Heartbeat) Reset() {
+	*h = Heartbeat{}
+}
+
+// Step moves dt seconds on with progress of the wave cleared and reports
+// whether a beat is due.
+func (h *Heartbeat) Step(dt float32, progress float32) bool {
+	h.timer -= dt
+	if h.timer > 0 {
+		return false
+	}
+
+	// carry over the overshoot so the tempo doesn't drift, unless the wait
+	// got so long that catching up would double up beats
+	h.timer = max(0, h.timer+BeatInterval(progress))
+	h.High = !h.High
+	return true
+}
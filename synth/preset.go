@@ -0,0 +1,99 @@
+package synth
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Waveform string
+
+const (
+	Square   Waveform = "square"
+	Sawtooth Waveform = "sawtooth"
+	Triangle Waveform = "triangle"
+	Sine     Waveform = "sine"
+	// Noise picks a new random level every time the phase wraps, so the
+	// frequency sets how coarse it sounds
+	Noise Waveform = "noise"
+)
+
+// MaxLength caps how many seconds a preset can last, so a typo in the data
+// file can't render minutes of audio.
+const MaxLength float32 = 5
+
+// Preset describes a sound the way sfxr does: an oscillator whose pitch can
+// sweep and wobble, shaped by an ADSR envelope.
+type Preset struct {
+	Waveform Waveform `json:"waveform"`
+	// Frequency is the starting pitch in Hz. Slide sweeps it by that many
+	// octaves a second, never going below MinFrequency.
+	Frequency    float32 `json:"frequency"`
+	Slide        float32 `json:"slide"`
+	MinFrequency float32 `json:"minFrequency"`
+	// DutyCycle is the fraction of a square wave spent high, 0.5 when left out
+	DutyCycle float32 `json:"dutyCycle"`
+	// VibratoDepth is the fraction the pitch wobbles by, VibratoSpeed times a
+	// second
+	VibratoDepth float32 `json:"vibratoDepth"`
+	VibratoSpeed float32 `json:"vibratoSpeed"`
+	// Attack, Decay, SustainTime and Release are in seconds. The volume rises
+	// to full over Attack, falls to SustainLevel over Decay, holds there for
+	// SustainTime and fades out over Release.
+	Attack       float32 `json:"attack"`
+	Decay        float32 `json:"decay"`
+	SustainLevel float32 `json:"sustainLevel"`
+	SustainTime  float32 `json:"sustainTime"`
+	Release      float32 `json:"release"`
+	Volume       float32 `json:"volume"`
+}
+
+// Length is how many seconds the sound lasts.
+func (p *Preset) Length() float32 {
+	return p.Attack + p.Decay + p.SustainTime + p.Release
+}
+
+func (p *Preset) Validate() error {
+	switch p.Waveform {
+	case Square, Sawtooth, Triangle, Sine, Noise:
+	default:
+		return fmt.Errorf("unknown waveform %q", p.Waveform)
+	}
+
+	if p.Frequency <= 0 || p.MinFrequency < 0 {
+		return fmt.Errorf("frequency %v and minimum %v must be positive", p.Frequency, p.MinFrequency)
+	}
+	if p.DutyCycle < 0 || p.DutyCycle >= 1 {
+		return fmt.Errorf("duty cycle %v is out of range", p.DutyCycle)
+	}
+	if p.VibratoDepth < 0 || p.VibratoDepth >= 1 || p.VibratoSpeed < 0 {
+		return fmt.Errorf("vibrato %v at %v is out of range", p.VibratoDepth, p.VibratoSpeed)
+	}
+	if p.Attack < 0 || p.Decay < 0 || p.SustainTime < 0 || p.Release < 0 {
+		return fmt.Errorf("envelope times can't be negative")
+	}
+	if length := p.Length(); length <= 0 || length > MaxLength {
+		return fmt.Errorf("length %v is out of range", length)
+	}
+	if p.SustainLevel < 0 || p.SustainLevel > 1 || p.Volume < 0 || p.Volume > 1 {
+		return fmt.Errorf("sustain level %v and volume %v must be between 0 and 1", p.SustainLevel, p.Volume)
+	}
+
+	return nil
+}
+
+// LoadPresets reads a JSON object of presets by name, refusing the lot if any
+// of them is invalid.
+func LoadPresets(contents []byte) (map[string]Preset, error) {
+	var presets map[string]Preset
+	if err := json.Unmarshal(contents, &presets); err != nil {
+		return nil, err
+	}
+
+	for name, preset := range presets {
+		if err := preset.Validate(); err != nil {
+			return nil, fmt.Errorf("preset %s: %w", name, err)
+		}
+	}
+
+	return presets, nil
+}
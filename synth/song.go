@@ -0,0 +1,161 @@
+package synth
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxSongLength caps how many seconds a song's loop can last.
+const MaxSongLength float32 = 30
+
+// Music is the game's songs along with the instruments they are played on.
+type Music struct {
+	Instruments map[string]Preset `json:"instruments"`
+	Songs       map[string]Song   `json:"songs"`
+}
+
+// Song is a loop of steps, StepsPerBeat to a beat at Tempo beats a minute.
+// The loop is as long as its longest part, and shorter parts repeat to fill
+// it.
+type Song struct {
+	Tempo        float32 `json:"tempo"`
+	StepsPerBeat int     `json:"stepsPerBeat"`
+	Parts        []Part  `json:"parts"`
+}
+
+// Part plays an instrument on each step that has a note, the number of
+// semitones above the instrument's frequency. A null step is a rest.
+type Part struct {
+	Instrument string     `json:"instrument"`
+	Notes      []*float32 `json:"notes"`
+}
+
+// LoadMusic reads the instruments and songs from JSON, refusing the lot if
+// any of them is invalid.
+func LoadMusic(contents []byte) (*Music, error) {
+	var music Music
+	if err := json.Unmarshal(contents, &music); err != nil {
+		return nil, err
+	}
+
+	for name, instrument := range music.Instruments {
+		if err := instrument.Validate(); err != nil {
+			return nil, fmt.Errorf("instrument %s: %w", name, err)
+		}
+	}
+	for name, song := range music.Songs {
+		if err := music.validate(&song); err != nil {
+			return nil, fmt.Errorf("song %s: %w", name, err)
+		}
+	}
+
+	return &music, nil
+}
+
+func (m *Music) validate(song *Song) error {
+	if song.Tempo <= 0 || song.StepsPerBeat <= 0 {
+		return fmt.Errorf("tempo %v and steps per beat %d must be above 0", song.Tempo, song.StepsPerBeat)
+	}
+	if len(song.Parts) == 0 {
+		return errors.New("a song needs at least one part")
+	}
+
+	var steps = song.Steps()
+	for i, part := range song.Parts {
+		if _, ok := m.Instruments[part.Instrument]; !ok {
+			return fmt.Errorf("parts[%d]: no instrument %q", i, part.Instrument)
+		}
+		if len(part.Notes) == 0 || steps%len(part.Notes) != 0 {
+			return fmt.Errorf("parts[%d]: %d steps don't repeat evenly over the %d step loop", i, len(part.Notes), steps)
+		}
+	}
+
+	if length := song.Length(); length > MaxSongLength {
+		return fmt.Errorf("length %v is over %v seconds", length, MaxSongLength)
+	}
+	return nil
+}
+
+// Steps is how many steps the loop lasts.
+func (s *Song) Steps() int {
+	var steps = 0
+	for _, part := range s.Parts {
+		steps = max(steps, len(part.Notes))
+	}
+	return steps
+}
+
+// Length is how many seconds the loop lasts.
+func (s *Song) Length() float32 {
+	return float32(s.Steps()) * 60 / (s.Tempo * float32(s.StepsPerBeat))
+}
+
+// Render generates one loop of the song as mono 16-bit samples. Notes still
+// ringing at the end wrap round to the start, so the loop repeats without a
+// seam.
+func (m *Music) Render(song *Song, sampleRate int) []int16 {
+	var mix = make([]float32, int(song.Length()*float32(sampleRate)))
+	var stepSamples = float64(len(mix)) / float64(song.Steps())
+
+	for _, part := range song.Parts {
+		var instrument = m.Instruments[part.Instrument]
+		// each pitch is only rendered once however often it is played
+		var rendered = map[float32][]int16{}
+		for step := range song.Steps() {
+			var note = part.Notes[step%len(part.Notes)]
+			if note == nil {
+				continue
+			}
+
+			var samples, ok = rendered[*note]
+			if !ok {
+				var tuned = instrument
+				var scale = float32(math.Pow(2, float64(*note)/12))
+				tuned.Frequency *= scale
+				tuned.MinFrequency *= scale
+				samples = tuned.Render(sampleRate)
+				rendered[*note] = samples
+			}
+
+			var start = int(float64(step) * stepSamples)
+			for i, sample := range samples {
+				mix[(start+i)%len(mix)] += float32(sample)
+			}
+		}
+	}
+
+	var samples = make([]int16, len(mix))
+	for i, value := range mix {
+		samples[i] = int16(max(math.MinInt16, min(math.MaxInt16, value)))
+	}
+	return samples
+}
+
+// EncodeWAV wraps mono 16-bit samples in a WAV file.
+func EncodeWAV(samples []int16, sampleRate int) []byte {
+	const headerSize = 44
+	var dataSize = 2 * len(samples)
+	var wav = make([]byte, headerSize+dataSize)
+
+	copy(wav[0:], "RIFF")
+	binary.LittleEndian.PutUint32(wav[4:], uint32(headerSize-8+dataSize))
+	copy(wav[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(wav[16:], 16)
+	// uncompressed PCM, one channel
+	binary.LittleEndian.PutUint16(wav[20:], 1)
+	binary.LittleEndian.PutUint16(wav[22:], 1)
+	binary.LittleEndian.PutUint32(wav[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(wav[28:], uint32(2*sampleRate))
+	binary.LittleEndian.PutUint16(wav[32:], 2)
+	binary.LittleEndian.PutUint16(wav[34:], 16)
+	copy(wav[36:], "data")
+	binary.LittleEndian.PutUint32(wav[40:], uint32(dataSize))
+
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(wav[headerSize+2*i:], uint16(sample))
+	}
+	return wav
+}
@@ -0,0 +1,92 @@
+package synth
+
+import (
+	"encoding/binary"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadMusic(t *testing.T) {
+	var tests = []struct {
+		name  string
+		json  string
+		error string
+	}{
+		{"no parts", `{"songs": {"x": {"tempo": 120, "stepsPerBeat": 4}}}`, "part"},
+		{"no tempo", `{"instruments": {"a": {"waveform": "sine", "frequency": 440, "sustainTime": 0.1, "volume": 1}}, "songs": {"x": {"stepsPerBeat": 4, "parts": [{"instrument": "a", "notes": [0]}]}}}`, "tempo"},
+		{"unknown instrument", `{"songs": {"x": {"tempo": 120, "stepsPerBeat": 4, "parts": [{"instrument": "kazoo", "notes": [0]}]}}}`, "kazoo"},
+		{"uneven parts", `{"instruments": {"a": {"waveform": "sine", "frequency": 440, "sustainTime": 0.1, "volume": 1}}, "songs": {"x": {"tempo": 120, "stepsPerBeat": 4, "parts": [{"instrument": "a", "notes": [0, null, null]}, {"instrument": "a", "notes": [0, null, null, null]}]}}}`, "repeat evenly"},
+		{"too long", `{"instruments": {"a": {"waveform": "sine", "frequency": 440, "sustainTime": 0.1, "volume": 1}}, "songs": {"x": {"tempo": 1, "stepsPerBeat": 1, "parts": [{"instrument": "a", "notes": [0]}]}}}`, "length"},
+		{"bad instrument", `{"instruments": {"a": {"waveform": "sine", "frequency": -1, "sustainTime": 0.1, "volume": 1}}}`, "instrument a"},
+	}
+	for _, test := range tests {
+		if _, err := LoadMusic([]byte(test.json)); err == nil || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: got error %v, want one about %s", test.name, err, test.error)
+		}
+	}
+
+	var contents, err = os.ReadFile("../assets/audio/music.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	music, err := LoadMusic(contents)
+	if err != nil {
+		t.Fatalf("game music is invalid: %v", err)
+	}
+	for _, name := range []string{"menu", "game", "game_intense", "game_over"} {
+		var song, ok = music.Songs[name]
+		if !ok {
+			t.Errorf("no %s song", name)
+			continue
+		}
+		var samples = music.Render(&song, SampleRate)
+		if len(samples) != int(song.Length()*SampleRate) || slices.Max(samples) == 0 {
+			t.Errorf("%s renders %d samples, want %v seconds of sound", name, len(samples), song.Length())
+		}
+	}
+
+	// the intense layer plays over the game track, so they must loop together
+	var game, intense = music.Songs["game"], music.Songs["game_intense"]
+	if game.Length() != intense.Length() {
+		t.Errorf("game loop lasts %v seconds but its intense layer %v", game.Length(), intense.Length())
+	}
+}
+
+func TestRenderSongWrapsRound(t *testing.T) {
+	var note float32 = 12
+	var instrument = tone(Square, 440)
+	instrument.SustainTime = 0.4
+	var music = &Music{Instruments: map[string]Preset{"a": instrument}}
+	// the note starts on the last of 4 quarter second steps, so all but its
+	// first quarter second wraps round to the start
+	var song = Song{Tempo: 60, StepsPerBeat: 4, Parts: []Part{{Instrument: "a", Notes: []*float32{nil, nil, nil, &note}}}}
+
+	var samples = music.Render(&song, SampleRate)
+	if len(samples) != SampleRate {
+		t.Fatalf("rendered %d samples, want a second's worth", len(samples))
+	}
+	if cycles := risingCrossings(samples[:SampleRate/10]); cycles < 86 || cycles > 90 {
+		t.Errorf("start of the loop has %d cycles of the wrapped note, want an octave up at 88", cycles)
+	}
+	if slices.Max(samples[SampleRate/5:SampleRate*3/4]) != 0 {
+		t.Errorf("sound between the end of the wrapped note and its start")
+	}
+}
+
+func TestEncodeWAV(t *testing.T) {
+	var wav = EncodeWAV([]int16{1, -1, 300}, 22050)
+	if len(wav) != 44+6 || string(wav[:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+		t.Fatalf("malformed header % x", wav[:44])
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:]); rate != 22050 {
+		t.Errorf("sample rate %d, want 22050", rate)
+	}
+	if size := binary.LittleEndian.Uint32(wav[40:]); size != 6 {
+		t.Errorf("data size %d, want 6", size)
+	}
+	if last := int16(binary.LittleEndian.Uint16(wav[48:])); last != 300 {
+		t.Errorf("last sample %d, want 300", last)
+	}
+}
@@ -0,0 +1,81 @@
+// Package synth generates the game's sound effects and music from presets
+// instead of shipping them as recordings.
+package synth
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// SampleRate is what every sound is rendered at, in samples per second.
+const SampleRate = 44100
+
+// Render generates the preset as mono 16-bit samples. The noise is seeded the
+// same every time, so a preset always renders to the same samples.
+func (p *Preset) Render(sampleRate int) []int16 {
+	var samples = make([]int16, int(p.Length()*float32(sampleRate)))
+	var rng = rand.New(rand.NewPCG(1, 1))
+
+	var duty = float64(p.DutyCycle)
+	if duty == 0 {
+		duty = 0.5
+	}
+	var phase float64 = 0
+	var noise = rng.Float64()*2 - 1
+
+	for i := range samples {
+		var t = float64(i) / float64(sampleRate)
+
+		var frequency = float64(p.Frequency) * math.Pow(2, float64(p.Slide)*t)
+		frequency = max(frequency, float64(p.MinFrequency))
+		frequency *= 1 + float64(p.VibratoDepth)*math.Sin(2*math.Pi*float64(p.VibratoSpeed)*t)
+
+		phase += frequency / float64(sampleRate)
+		if phase >= 1 {
+			phase -= math.Floor(phase)
+			noise = rng.Float64()*2 - 1
+		}
+
+		var value float64
+		switch p.Waveform {
+		case Square:
+			value = 1
+			if phase >= duty {
+				value = -1
+			}
+		case Sawtooth:
+			value = 2*phase - 1
+		case Triangle:
+			value = 1 - 4*math.Abs(phase-0.5)
+		case Sine:
+			value = math.Sin(2 * math.Pi * phase)
+		case Noise:
+			value = noise
+		}
+
+		var level = float64(p.Envelope(float32(t)) * p.Volume)
+		samples[i] = int16(value * level * math.MaxInt16)
+	}
+
+	return samples
+}
+
+// Envelope is how loud the sound is at t seconds in, from 0 to 1.
+func (p *Preset) Envelope(t float32) float32 {
+	if t < p.Attack {
+		return t / p.Attack
+	}
+	t -= p.Attack
+	if t < p.Decay {
+		return 1 - (1-p.SustainLevel)*t/p.Decay
+	}
+	t -= p.Decay
+	if t < p.SustainTime {
+		return p.SustainLevel
+	}
+	t -= p.SustainTime
+	if t < p.Release {
+		return p.SustainLevel * (1 - t/p.Release)
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package synth
+
+import (
+	"math"
+	"os"
+	"slices"
+	"testing"
+)
+
+func tone(waveform Waveform, frequency float32) Preset {
+	return Preset{Waveform: waveform, Frequency: frequency, SustainLevel: 1, SustainTime: 1, Volume: 1}
+}
+
+// risingCrossings counts the times the samples go from negative to positive.
+func risingCrossings(samples []int16) int {
+	var count = 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRenderLength(t *testing.T) {
+	var preset = Preset{Waveform: Square, Frequency: 440, Attack: 0.1, Decay: 0.2, SustainLevel: 0.5, SustainTime: 0.3, Release: 0.4, Volume: 1}
+	var samples = preset.Render(SampleRate)
+	if len(samples) != SampleRate {
+		t.Errorf("%v seconds rendered to %d samples, want %d", preset.Length(), len(samples), SampleRate)
+	}
+}
+
+func TestRenderPitch(t *testing.T) {
+	for _, waveform := range []Waveform{Square, Sawtooth, Triangle, Sine} {
+		var preset = tone(waveform, 441)
+		if crossings := risingCrossings(preset.Render(SampleRate)); crossings < 439 || crossings > 442 {
+			t.Errorf("%s at 441Hz crossed zero %d times in a second", waveform, crossings)
+		}
+	}
+}
+
+func TestRenderSlide(t *testing.T) {
+	var preset = tone(Sine, 1000)
+	preset.Slide = -2
+	preset.MinFrequency = 300
+	var samples = preset.Render(SampleRate)
+
+	// 1000Hz halves to 500Hz over the first half second and bottoms out at
+	// 300Hz before the last tenth
+	var first = risingCrossings(samples[:SampleRate/2])
+	var last = risingCrossings(samples[SampleRate*9/10:])
+	if first < 340 || first > 380 {
+		t.Errorf("first half second crossed zero %d times, want about 360", first)
+	}
+	if last < 29 || last > 31 {
+		t.Errorf("last tenth of a second crossed zero %d times, want 30", last)
+	}
+}
+
+func TestRenderEnvelope(t *testing.T) {
+	var preset = Preset{Waveform: Square, Frequency: 100, Attack: 0.1, Decay: 0.1, SustainLevel: 0.5, SustainTime: 0.1, Release: 0.1, Volume: 0.8}
+	var samples = preset.Render(SampleRate)
+
+	var peak = func(from float32, to float32) int16 {
+		var window = samples[int(from*SampleRate):int(to*SampleRate)]
+		return max(slices.Max(window), -slices.Min(window))
+	}
+	var full = int16(math.MaxInt16 * 4 / 5)
+	if p := peak(0, 0.01); p > full/5 {
+		t.Errorf("attack starts at %d", p)
+	}
+	if p := peak(0.09, 0.11); p < full*9/10 {
+		t.Errorf("attack peaks at %d, want about %d", p, full)
+	}
+	if p := peak(0.22, 0.28); p < full/2-100 || p > full/2+100 {
+		t.Errorf("sustain is at %d, want %d", p, full/2)
+	}
+	if p := peak(0.39, 0.4); p > full/20 {
+		t.Errorf("release ends at %d", p)
+	}
+}
+
+func TestRenderNoiseIsRepeatable(t *testing.T) {
+	var preset = tone(Noise, 2000)
+	var samples = preset.Render(SampleRate)
+	if !slices.Equal(samples, preset.Render(SampleRate)) {
+		t.Errorf("noise rendered differently the second time")
+	}
+	if slices.Max(samples) == slices.Min(samples) {
+		t.Errorf("noise is silent")
+	}
+}
+
+func TestLoadPresets(t *testing.T) {
+	if _, err := LoadPresets([]byte(`{"bad": {"waveform": "kazoo", "frequency": 440, "sustainTime": 1, "volume": 1}}`)); err == nil {
+		t.Errorf("unknown waveform was accepted")
+	}
+	if _, err := LoadPresets([]byte(`{"long": {"waveform": "sine", "frequency": 440, "sustainTime": 60, "volume": 1}}`)); err == nil {
+		t.Errorf("a minute long preset was accepted")
+	}
+
+	var contents, err = os.ReadFile("../assets/audio/sfx.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	presets, err := LoadPresets(contents)
+	if err != nil {
+		t.Fatalf("game presets are invalid: %v", err)
+	}
+	for name, preset := range presets {
+		var samples = preset.Render(SampleRate)
+		if len(samples) == 0 || slices.Max(samples) == 0 {
+			t.Errorf("%s renders to silence", name)
+		}
+	}
+}
+
+func TestHeartbeatSpeedsUp(t *testing.T) {
+	var count = func(progress float32) int {
+		var heartbeat Heartbeat
+		var beats = 0
+		for range 10 * 120 {
+			if heartbeat.Step(1.0/120, progress) {
+				beats++
+			}
+		}
+		return beats
+	}
+
+	// the beat landing right on the 10 second mark may or may not count
+	if beats := count(0); beats < 10 || beats > 11 {
+		t.Errorf("%d beats in 10 seconds at the start of a wave, want 10", beats)
+	}
+	if beats := count(1); beats < 40 || beats > 41 {
+		t.Errorf("%d beats in 10 seconds at the end of a wave, want 40", beats)
+	}
+
+	var heartbeat Heartbeat
+	heartbeat.Step(0, 0)
+	var first = heartbeat.High
+	heartbeat.Step(SlowestBeat, 0)
+	if heartbeat.High == first {
+		t.Errorf("beats do not alternate")
+	}
+}